@@ -10,40 +10,27 @@ import (
 // reflect.Value for the struct because it needs access to both the Value and
 // Type of the struct.
 func sanitizeBoolField(s Sanitizer, structValue reflect.Value, idx int) error {
-	fieldValue := structValue.Field(idx)
-
-	tags := s.fieldTags(structValue.Type().Field(idx).Tag)
-
-	if fieldValue.Kind() == reflect.Ptr && !fieldValue.IsNil() {
-		fieldValue = fieldValue.Elem()
-	}
-
-	isSlice := fieldValue.Kind() == reflect.Slice
-
-	var fields []reflect.Value
-	if !isSlice {
-		fields = []reflect.Value{fieldValue}
-	} else {
-		for i := 0; i < fieldValue.Len(); i++ {
-			fields = append(fields, fieldValue.Index(i))
-		}
-	}
-
-	for _, field := range fields {
-		isPtr := field.Kind() == reflect.Ptr
-
-		// Only handle "def". No min or max etc.
-		if isPtr && field.IsNil() {
-			if _, ok := tags["def"]; ok {
-				defBool, err := strconv.ParseBool(tags["def"])
-				if err != nil {
-					return fmt.Errorf("unable to parse default bool value: %+v", err)
-				}
+	return s.sanitizeScalarField(structValue, idx, compileBoolRule)
+}
 
-				field.Set(reflect.ValueOf(&defBool))
+// compileBoolRule parses the tag components of a bool field once. Only "def"
+// is handled, there is no min or max etc.
+func compileBoolRule(s *Sanitizer, t reflect.Type, tags map[string]string, order []string) (*scalarRule, error) {
+	tags = s.builtinTags(tags)
+	rule := &scalarRule{}
+
+	// The default is only reported as invalid once it is needed, so that a
+	// bad "def" on a non-pointer field does not fail the whole struct.
+	if _, ok := tags["def"]; ok {
+		defBool, err := strconv.ParseBool(tags["def"])
+		rule.def = func(field reflect.Value) error {
+			if err != nil {
+				return tagError("def", fmt.Errorf("unable to parse default bool value: %+v", err))
 			}
+			field.SetBool(defBool)
+			return nil
 		}
 	}
 
-	return nil
+	return rule, nil
 }
@@ -0,0 +1,105 @@
+package sanitize
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+// copier makes deep copies of values so that they can be sanitized without
+// changing the original. It remembers the pointers it already copied, so
+// shared data keeps the same shape in the copy. Cyclic data is copied too,
+// but can not be sanitized, see Sanitize.
+type copier struct {
+	seen map[copiedPtr]reflect.Value
+}
+
+type copiedPtr struct {
+	t   reflect.Type
+	ptr uintptr
+}
+
+// deepCopy returns an addressable deep copy of v. Pointers, slices, arrays,
+// maps, the exported fields of structs and unexported embedded structs or
+// pointers to them are copied; everything else, such as interfaces, funcs,
+// channels and other unexported fields, is shared with v.
+func deepCopy(v reflect.Value) reflect.Value {
+	c := copier{seen: map[copiedPtr]reflect.Value{}}
+	dst := reflect.New(v.Type()).Elem()
+	c.copyInto(dst, v)
+	return dst
+}
+
+func (c copier) copyInto(dst, src reflect.Value) {
+	switch src.Kind() {
+	case reflect.Ptr:
+		if src.IsNil() {
+			return
+		}
+		key := copiedPtr{t: src.Type(), ptr: src.Pointer()}
+		if p, ok := c.seen[key]; ok {
+			dst.Set(p)
+			return
+		}
+		p := reflect.New(src.Type().Elem())
+		c.seen[key] = p
+		c.copyInto(p.Elem(), src.Elem())
+		dst.Set(p)
+	case reflect.Slice:
+		if src.IsNil() {
+			return
+		}
+		s := reflect.MakeSlice(src.Type(), src.Len(), src.Len())
+		for i := 0; i < src.Len(); i++ {
+			c.copyInto(s.Index(i), src.Index(i))
+		}
+		dst.Set(s)
+	case reflect.Array:
+		for i := 0; i < src.Len(); i++ {
+			c.copyInto(dst.Index(i), src.Index(i))
+		}
+	case reflect.Map:
+		if src.IsNil() {
+			return
+		}
+		m := reflect.MakeMapWithSize(src.Type(), src.Len())
+		elem := reflect.New(src.Type().Elem()).Elem()
+		for _, k := range src.MapKeys() {
+			elem.Set(reflect.Zero(elem.Type()))
+			c.copyInto(elem, src.MapIndex(k))
+			m.SetMapIndex(k, elem)
+		}
+		dst.Set(m)
+	case reflect.Struct:
+		// Copy the whole struct first so that unexported fields are kept,
+		// then replace the fields that can be reached by the sanitizer.
+		dst.Set(src)
+		c.copyFields(dst, src)
+	default:
+		dst.Set(src)
+	}
+}
+
+func (c copier) copyFields(dst, src reflect.Value) {
+	t := src.Type()
+	for i := 0; i < src.NumField(); i++ {
+		field := dst.Field(i)
+		if field.CanSet() {
+			field.Set(reflect.Zero(field.Type()))
+			c.copyInto(field, src.Field(i))
+		} else if t.Field(i).Anonymous && field.Kind() == reflect.Struct {
+			// The exported fields of an unexported embedded struct can still
+			// be set, and are sanitized.
+			c.copyFields(field, src.Field(i))
+		} else if t.Field(i).Anonymous && field.Kind() == reflect.Ptr {
+			// The same goes for the struct behind an unexported embedded
+			// pointer, so it is copied too. reflect does not allow setting
+			// the pointer itself, hence the unsafe alias of the field, which
+			// dst already holds a copy of.
+			alias := reflect.NewAt(field.Type(), unsafe.Pointer(field.UnsafeAddr())).Elem()
+			orig := reflect.New(alias.Type()).Elem()
+			orig.Set(alias)
+			alias.Set(reflect.Zero(alias.Type()))
+			c.copyInto(alias, orig)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package sanitize
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// FieldError is returned when a field can not be sanitized, or when its tag
+// is not valid. Use errors.As to get it from the error returned by Sanitize.
+type FieldError struct {
+	// Path leads from the value given to the Sanitizer to the failing
+	// field, e.g. "Items[3].Price". It is empty for the value itself.
+	Path string
+	// Tag is the tag component that failed, e.g. "max". It is empty when
+	// the error is not tied to a single component.
+	Tag string
+	// Err is the underlying cause.
+	Err error
+}
+
+func (e *FieldError) Error() string {
+	var b strings.Builder
+	if e.Path != "" {
+		fmt.Fprintf(&b, "field %q", e.Path)
+	}
+	if e.Tag != "" {
+		if b.Len() > 0 {
+			b.WriteString(" ")
+		}
+		fmt.Fprintf(&b, "(%s)", e.Tag)
+	}
+	if b.Len() > 0 {
+		b.WriteString(": ")
+	}
+	b.WriteString(e.Err.Error())
+	return b.String()
+}
+
+// Unwrap returns the underlying cause.
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// MultiError holds every error found in a value when the Sanitizer was
+// created with OptionCollectErrors. Errors are in the order they were found.
+type MultiError []error
+
+func (m MultiError) Error() string {
+	if len(m) == 1 {
+		return m[0].Error()
+	}
+	msgs := make([]string, len(m))
+	for i, err := range m {
+		msgs[i] = err.Error()
+	}
+	return fmt.Sprintf("%d errors: %s", len(m), strings.Join(msgs, "; "))
+}
+
+// As finds the first collected error that matches target, so that errors.As
+// looks into each of them.
+func (m MultiError) As(target interface{}) bool {
+	for _, err := range m {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Is reports whether any of the collected errors matches target, so that
+// errors.Is looks into each of them.
+func (m MultiError) Is(target error) bool {
+	for _, err := range m {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// tagError ties err to the tag component tag. The path is filled in as the
+// error travels up to the field.
+func tagError(tag string, err error) *FieldError {
+	return &FieldError{Tag: tag, Err: err}
+}
+
+// prefixPath puts the path segment seg in front of the path of err, turning
+// err into a *FieldError if it is not one yet. Segments that start with "["
+// are indexes and are not separated by a dot.
+func prefixPath(seg string, err error) error {
+	switch e := err.(type) {
+	case *FieldError:
+		return &FieldError{Path: joinPath(seg, e.Path), Tag: e.Tag, Err: e.Err}
+	case MultiError:
+		m := make(MultiError, len(e))
+		for i, err := range e {
+			m[i] = prefixPath(seg, err)
+		}
+		return m
+	}
+	return &FieldError{Path: seg, Err: err}
+}
+
+func joinPath(a, b string) string {
+	if a == "" {
+		return b
+	}
+	if b == "" {
+		return a
+	}
+	if strings.HasPrefix(b, "[") {
+		return a + b
+	}
+	return a + "." + b
+}
+
+// pathSegment is one step from a value to the value it holds: a struct field
+// when field is set, a map key when key is valid, or a slice index otherwise.
+type pathSegment struct {
+	field string
+	key   reflect.Value
+	index int
+}
+
+func (p pathSegment) String() string {
+	if p.field != "" {
+		return p.field
+	}
+	if p.key.IsValid() {
+		if p.key.Kind() == reflect.String {
+			return "[" + strconv.Quote(p.key.String()) + "]"
+		}
+		return fmt.Sprintf("[%v]", p.key.Interface())
+	}
+	return "[" + strconv.Itoa(p.index) + "]"
+}
+
+// walker carries the state of a single walk through the plans. The path is
+// only turned into a string when an error is found.
+type walker struct {
+	s       *Sanitizer
+	path    []pathSegment
+	collect bool
+	errs    MultiError
+}
+
+func newWalker(s *Sanitizer) *walker {
+	return &walker{
+		s:       s,
+		path:    make([]pathSegment, 0, 8),
+		collect: s.collectErrors,
+	}
+}
+
+func (w *walker) push(seg pathSegment) {
+	w.path = append(w.path, seg)
+}
+
+func (w *walker) pop() {
+	w.path = w.path[:len(w.path)-1]
+}
+
+// fail ties err to the current path. It returns the error to stop the walk,
+// or nil when errors are being collected and the walk can go on.
+func (w *walker) fail(tag string, err error) error {
+	var path string
+	for _, seg := range w.path {
+		path = joinPath(path, seg.String())
+	}
+
+	fe, ok := err.(*FieldError)
+	if ok {
+		fe = &FieldError{Path: joinPath(path, fe.Path), Tag: fe.Tag, Err: fe.Err}
+	} else {
+		fe = &FieldError{Path: path, Tag: tag, Err: err}
+	}
+
+	if w.collect {
+		w.errs = append(w.errs, fe)
+		return nil
+	}
+	return fe
+}
+
+// err returns the collected errors, if any.
+func (w *walker) err() error {
+	if len(w.errs) == 0 {
+		return nil
+	}
+	return w.errs
+}
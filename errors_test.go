@@ -0,0 +1,242 @@
+package sanitize
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var errNegative = errors.New("value is negative")
+
+// positive fails on negative int64 values.
+func positive(s Sanitizer, structValue reflect.Value, idx int) error {
+	if structValue.Field(idx).Int() < 0 {
+		return errNegative
+	}
+	return nil
+}
+
+type testErrorsItem struct {
+	Name  string `san:"trim"`
+	Price int64  `san:"positive"`
+}
+
+type testErrorsOrder struct {
+	ID    string `san:"trim"`
+	Items []testErrorsItem
+	Extra *testErrorsItem
+}
+
+func Test_FieldError_Error(t *testing.T) {
+	cause := errors.New("boom")
+
+	tests := []struct {
+		name string
+		err  *FieldError
+		want string
+	}{
+		{
+			name: "Path and tag",
+			err:  &FieldError{Path: "Items[3].Price", Tag: "max", Err: cause},
+			want: `field "Items[3].Price" (max): boom`,
+		},
+		{
+			name: "Path only",
+			err:  &FieldError{Path: "Name", Err: cause},
+			want: `field "Name": boom`,
+		},
+		{
+			name: "Tag only",
+			err:  &FieldError{Tag: "max", Err: cause},
+			want: `(max): boom`,
+		},
+		{
+			name: "Cause only",
+			err:  &FieldError{Err: cause},
+			want: `boom`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.err.Error(); got != tt.want {
+				t.Errorf("FieldError.Error() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_Sanitize_FieldError(t *testing.T) {
+	s, _ := New(OptionSanitizerFunc{Name: "positive", Sanitizer: positive})
+
+	o := &testErrorsOrder{
+		ID: " order ",
+		Items: []testErrorsItem{
+			{Name: " a ", Price: 1},
+			{Name: " b ", Price: 1},
+			{Name: " c ", Price: 1},
+			{Name: " d ", Price: -1},
+			{Name: " e ", Price: -1},
+		},
+	}
+
+	err := s.Sanitize(o)
+	if err == nil {
+		t.Fatal("Sanitize() - did not receive expected error")
+	}
+
+	var fe *FieldError
+	if !errors.As(err, &fe) {
+		t.Fatalf("Sanitize() - got error %T, wanted a *FieldError", err)
+	}
+	if fe.Path != "Items[3].Price" {
+		t.Errorf("FieldError.Path = %q, want %q", fe.Path, "Items[3].Price")
+	}
+	if fe.Tag != "positive" {
+		t.Errorf("FieldError.Tag = %q, want %q", fe.Tag, "positive")
+	}
+	if !errors.Is(err, errNegative) {
+		t.Errorf("Sanitize() - error %v does not wrap the cause", err)
+	}
+
+	// Stopped at the first error
+	if o.Items[4].Name != " e " {
+		t.Errorf("Sanitize() - kept sanitizing after the first error")
+	}
+}
+
+func Test_Sanitize_MultiError(t *testing.T) {
+	s, _ := New(
+		OptionSanitizerFunc{Name: "positive", Sanitizer: positive},
+		OptionCollectErrors{Value: true},
+	)
+
+	o := &testErrorsOrder{
+		ID: " order ",
+		Items: []testErrorsItem{
+			{Name: " a ", Price: -1},
+			{Name: " b ", Price: 1},
+			{Name: " c ", Price: -1},
+		},
+		Extra: &testErrorsItem{Name: " x ", Price: -1},
+	}
+	want := &testErrorsOrder{
+		ID: "order",
+		Items: []testErrorsItem{
+			{Name: "a", Price: -1},
+			{Name: "b", Price: 1},
+			{Name: "c", Price: -1},
+		},
+		Extra: &testErrorsItem{Name: "x", Price: -1},
+	}
+
+	err := s.Sanitize(o)
+	if err == nil {
+		t.Fatal("Sanitize() - did not receive expected error")
+	}
+
+	var m MultiError
+	if !errors.As(err, &m) {
+		t.Fatalf("Sanitize() - got error %T, wanted a MultiError", err)
+	}
+
+	var paths []string
+	for _, err := range m {
+		var fe *FieldError
+		if !errors.As(err, &fe) {
+			t.Fatalf("MultiError - got error %T, wanted a *FieldError", err)
+		}
+		paths = append(paths, fe.Path)
+	}
+	wantPaths := []string{"Items[0].Price", "Items[2].Price", "Extra.Price"}
+	if !reflect.DeepEqual(paths, wantPaths) {
+		t.Errorf("MultiError - got paths %v but wanted %v", paths, wantPaths)
+	}
+
+	var fe *FieldError
+	if !errors.As(err, &fe) || fe.Path != "Items[0].Price" {
+		t.Errorf("errors.As() - did not find the first *FieldError in %v", err)
+	}
+	if !errors.Is(err, errNegative) {
+		t.Errorf("errors.Is() - did not find the cause in %v", err)
+	}
+	if errors.Is(err, errors.New("value is negative")) {
+		t.Errorf("errors.Is() - matched an unrelated error in %v", err)
+	}
+	if !strings.HasPrefix(err.Error(), "3 errors: ") {
+		t.Errorf("MultiError.Error() = %q", err.Error())
+	}
+
+	if !reflect.DeepEqual(o, want) {
+		t.Errorf("Sanitize() - got %+v but wanted %+v", o, want)
+	}
+}
+
+func Test_Sanitize_MultiError_Tags(t *testing.T) {
+	type TestSub struct {
+		Age int `san:"max=abc"`
+	}
+	type TestStruct struct {
+		Name  string `san:"max=-1"`
+		Count uint   `san:"min=5,max=2"`
+		Sub   TestSub
+	}
+
+	s, _ := New(OptionCollectErrors{Value: true})
+
+	err := s.Validate(TestStruct{})
+
+	var m MultiError
+	if !errors.As(err, &m) {
+		t.Fatalf("Validate() - got error %v, wanted a MultiError", err)
+	}
+
+	var got []string
+	for _, err := range m {
+		fe := err.(*FieldError)
+		got = append(got, fmt.Sprintf("%s/%s", fe.Path, fe.Tag))
+	}
+	want := []string{"Name/max", "Count/max", "Sub.Age/max"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Validate() - got errors %v but wanted %v", got, want)
+	}
+}
+
+func Test_Sanitize_MultiError_BadTags(t *testing.T) {
+	type TestSub struct {
+		Good string `san:"trim"`
+		Bad  int    `san:"max=x"`
+	}
+	type TestStruct struct {
+		Good string `san:"trim"`
+		Bad  int    `san:"max=x"`
+		Subs []TestSub
+	}
+
+	s, _ := New(OptionCollectErrors{Value: true})
+
+	// The plans are cached, so the bad tags must be reported every time
+	for i := 0; i < 2; i++ {
+		v := &TestStruct{Good: " g ", Subs: []TestSub{{Good: " a "}, {Good: " b "}}}
+		err := s.Sanitize(v)
+
+		var m MultiError
+		if !errors.As(err, &m) {
+			t.Fatalf("Sanitize() - got error %v, wanted a MultiError", err)
+		}
+		var got []string
+		for _, err := range m {
+			fe := err.(*FieldError)
+			got = append(got, fmt.Sprintf("%s/%s", fe.Path, fe.Tag))
+		}
+		want := []string{"Bad/max", "Subs[0].Bad/max", "Subs[1].Bad/max"}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Sanitize() - got errors %v but wanted %v", got, want)
+		}
+
+		if v.Good != "g" || v.Subs[0].Good != "a" || v.Subs[1].Good != "b" {
+			t.Errorf("Sanitize() - did not sanitize the good fields of %+v", v)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package sanitize
+
+import (
+	"fmt"
+	"math"
+)
+
+// nonFiniteAction is what a floatCheck does with the values it catches.
+type nonFiniteAction int
+
+const (
+	nonFiniteError nonFiniteAction = iota
+	nonFiniteZero
+	nonFiniteDef
+)
+
+// floatCheck catches NaN, and infinities too for "finite", before any other
+// float tag component sees them: NaN fails every comparison, so min and max
+// would let it through, and they would clamp infinities.
+type floatCheck struct {
+	tag    string
+	inf    bool
+	action nonFiniteAction
+}
+
+// compileFloatCheck parses the "nonan" and "finite" tag components of a float
+// field. It returns nil if there are none. hasDef tells whether the field has
+// a def value to replace caught values with.
+func compileFloatCheck(tags map[string]string, hasDef bool) (*floatCheck, error) {
+	if err := checkConflicts(tags, floatNonFiniteTags); err != nil {
+		return nil, err
+	}
+
+	c := &floatCheck{}
+	v, ok := tags["nonan"]
+	if ok {
+		c.tag = "nonan"
+	} else if v, ok = tags["finite"]; ok {
+		c.tag = "finite"
+		c.inf = true
+	} else {
+		return nil, nil
+	}
+
+	switch v {
+	case "", "error":
+		c.action = nonFiniteError
+	case "zero":
+		c.action = nonFiniteZero
+	case "def":
+		if !hasDef {
+			return nil, tagError(c.tag, fmt.Errorf("%s=def needs a def tag component", c.tag))
+		}
+		c.action = nonFiniteDef
+	default:
+		return nil, tagError(c.tag, fmt.Errorf("unknown %s action %q, expected error, zero or def", c.tag, v))
+	}
+	return c, nil
+}
+
+// catches reports whether x must be replaced or rejected.
+func (c *floatCheck) catches(x float64) bool {
+	return math.IsNaN(x) || c.inf && math.IsInf(x, 0)
+}
+
+// err returns the error reported for a caught value x.
+func (c *floatCheck) err(x float64) error {
+	return tagError(c.tag, fmt.Errorf("%v is not allowed", x))
+}
@@ -15,7 +15,7 @@ func Test_sanitizeFloat64Field(t *testing.T) {
 		Field float64 `san:"max=41.1,min=-2"`
 	}
 	type TestFloat64StructNegativeMaxTag struct {
-		Field float64 `san:"max=-2,min=42.2"`
+		Field float64 `san:"max=-2,min=-42.2"`
 	}
 	type TestFloat64StructBadMaxMin struct {
 		Field float64 `san:"max=41.1,min=42.2"`
@@ -88,20 +88,20 @@ func Test_sanitizeFloat64Field(t *testing.T) {
 			wantErr: false,
 		},
 		{
-			name: "Returns an error if a san:min tag on a float64 field is below 0.",
+			name: "Raises a float64 field to a negative san:min tag.",
 			args: args{
 				v: &TestFloat64StructNegativeMinTag{
-					Field: 40,
+					Field: -5,
 				},
 				idx: 0,
 			},
 			want: &TestFloat64StructNegativeMinTag{
-				Field: 40,
+				Field: -2,
 			},
-			wantErr: true,
+			wantErr: false,
 		},
 		{
-			name: "Returns an error if a san:max tag on a float64 field is below 0.",
+			name: "Caps a float64 field to a negative san:max tag.",
 			args: args{
 				v: &TestFloat64StructNegativeMaxTag{
 					Field: 40,
@@ -109,9 +109,9 @@ func Test_sanitizeFloat64Field(t *testing.T) {
 				idx: 0,
 			},
 			want: &TestFloat64StructNegativeMaxTag{
-				Field: 40,
+				Field: -2,
 			},
-			wantErr: true,
+			wantErr: false,
 		},
 		{
 			name: "Returns an error if a san:min tag on a float64 field is not numeric.",
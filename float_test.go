@@ -0,0 +1,125 @@
+package sanitize
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+func Test_Sanitize_NonFinite(t *testing.T) {
+	type TestNonFinite struct {
+		Error   float64  `san:"nonan"`
+		Zero    float64  `san:"nonan=zero,min=1"`
+		Def     float32  `san:"nonan=def,def=2.5"`
+		Finite  *float64 `san:"finite=zero,max=10"`
+		FinDef  float64  `san:"finite=def,def=-1,min=-5,max=5"`
+		Clamped float64  `san:"max=10"`
+	}
+
+	nan := math.NaN()
+	inf := math.Inf(1)
+
+	tests := []struct {
+		name    string
+		v       TestNonFinite
+		check   func(v TestNonFinite) bool
+		wantErr string
+	}{
+		{
+			name: "Keeps finite values.",
+			v:    TestNonFinite{Error: 1.5, Zero: 2, Def: 3, Finite: func() *float64 { f := 4.0; return &f }(), FinDef: 4},
+			check: func(v TestNonFinite) bool {
+				return v.Error == 1.5 && v.Zero == 2 && v.Def == 3 && *v.Finite == 4 && v.FinDef == 4
+			},
+		},
+		{
+			name:  "Replaces NaN with zero, before applying min.",
+			v:     TestNonFinite{Zero: nan},
+			check: func(v TestNonFinite) bool { return v.Zero == 1 },
+		},
+		{
+			name:  "Replaces NaN with the default.",
+			v:     TestNonFinite{Def: float32(nan)},
+			check: func(v TestNonFinite) bool { return v.Def == 2.5 },
+		},
+		{
+			name:  "Lets infinities through nonan.",
+			v:     TestNonFinite{Error: inf, Zero: math.Inf(-1)},
+			check: func(v TestNonFinite) bool { return math.IsInf(v.Error, 1) && v.Zero == 1 },
+		},
+		{
+			name:  "Replaces infinities with zero when finite.",
+			v:     TestNonFinite{Finite: &inf},
+			check: func(v TestNonFinite) bool { return *v.Finite == 0 },
+		},
+		{
+			name:  "Replaces infinities with the default when finite, instead of clamping them.",
+			v:     TestNonFinite{FinDef: math.Inf(-1)},
+			check: func(v TestNonFinite) bool { return v.FinDef == -1 },
+		},
+		{
+			name:  "Clamps infinities without finite.",
+			v:     TestNonFinite{Clamped: inf},
+			check: func(v TestNonFinite) bool { return v.Clamped == 10 },
+		},
+		{
+			name:    "Returns an error for NaN.",
+			v:       TestNonFinite{Error: nan},
+			check:   func(v TestNonFinite) bool { return math.IsNaN(v.Error) },
+			wantErr: `field "Error" (nonan): NaN is not allowed`,
+		},
+	}
+	s, _ := New()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := tt.v
+			err := s.Sanitize(&v)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("Sanitize() - got error %v, wanted %q", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("Sanitize() - got unexpected error %v", err)
+			}
+			if !tt.check(v) {
+				t.Errorf("Sanitize() - got %+v", v)
+			}
+		})
+	}
+}
+
+func Test_Sanitize_NonFiniteError(t *testing.T) {
+	type TestFinite struct {
+		Value float64 `san:"finite=error"`
+	}
+
+	s, _ := New()
+
+	err := s.Sanitize(&TestFinite{Value: math.Inf(-1)})
+	var fe *FieldError
+	if !errors.As(err, &fe) || fe.Tag != "finite" || fe.Path != "Value" {
+		t.Errorf("Sanitize() - got error %v, wanted a finite error on Value", err)
+	}
+}
+
+func Test_compileFloatCheck_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		tags    map[string]string
+		hasDef  bool
+		wantErr string
+	}{
+		{name: "Rejects nonan and finite together.", tags: map[string]string{"nonan": "", "finite": ""}, wantErr: "conflicting tag components nonan and finite"},
+		{name: "Rejects def without a def value.", tags: map[string]string{"nonan": "def"}, wantErr: "nonan=def needs a def tag component"},
+		{name: "Rejects unknown actions.", tags: map[string]string{"finite": "clamp"}, hasDef: true, wantErr: `unknown finite action "clamp"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := compileFloatCheck(tt.tags, tt.hasDef)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("compileFloatCheck() - got error %v, wanted it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package sanitize
+
+import (
+	"reflect"
+)
+
+// Apply returns a sanitized copy of v, leaving v itself untouched. v can be a
+// struct, a pointer to a struct, or a slice, array or map of them; see
+// Sanitize for the rules that are applied.
+//
+//	clean, err := sanitize.Apply(s, dog)
+//
+// The copy is deep: the pointers, slices and maps v refers to are copied too,
+// including unexported embedded pointers to structs. Like with Sanitize,
+// cyclic values are not supported. If an error is returned, the returned
+// value may be partly sanitized.
+func Apply[T any](s *Sanitizer, v T) (T, error) {
+	c := deepCopy(reflect.ValueOf(&v).Elem())
+	err := s.Sanitize(c.Addr().Interface())
+	return c.Interface().(T), err
+}
+
+// Slice returns a sanitized copy of the batch vs, leaving vs and its elements
+// untouched. It works like Apply.
+func Slice[T any](s *Sanitizer, vs []T) ([]T, error) {
+	return Apply(s, vs)
+}
+
+// Value returns a copy of v sanitized with the components of tag, leaving v
+// untouched. It works like SanitizeValue.
+//
+//	age, err := sanitize.Value(s, age, "min=0,max=150")
+func Value[T any](s *Sanitizer, v T, tag string) (T, error) {
+	c := deepCopy(reflect.ValueOf(&v).Elem())
+	err := s.SanitizeValue(c.Addr().Interface(), tag)
+	return c.Interface().(T), err
+}
+
+// String returns v sanitized with the components of tag.
+//
+//	name, err := sanitize.String(s, " Borky Borkins ", "trim,lower")
+func String(s *Sanitizer, v string, tag string) (string, error) {
+	return Value(s, v, tag)
+}
@@ -0,0 +1,184 @@
+package sanitize
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_Apply(t *testing.T) {
+	s, _ := New()
+
+	orig := newTestPlanBench()
+	want := newTestPlanBench()
+
+	got, err := Apply(s, *orig)
+	if err != nil {
+		t.Fatalf("Apply() - got unexpected error %v", err)
+	}
+	if !reflect.DeepEqual(orig, want) {
+		t.Errorf("Apply() - modified its argument, got %+v but wanted %+v", orig, want)
+	}
+
+	sanitized := newTestPlanBench()
+	if err := s.Sanitize(sanitized); err != nil {
+		t.Fatalf("Sanitize() - got unexpected error %v", err)
+	}
+	if !reflect.DeepEqual(&got, sanitized) {
+		t.Errorf("Apply() - got %+v but wanted %+v", got, *sanitized)
+	}
+
+	gotPtr, err := Apply(s, orig)
+	if err != nil {
+		t.Fatalf("Apply() - got unexpected error %v", err)
+	}
+	if gotPtr == orig || !reflect.DeepEqual(orig, want) {
+		t.Errorf("Apply() - modified the struct its argument points to")
+	}
+	if !reflect.DeepEqual(gotPtr, sanitized) {
+		t.Errorf("Apply() - got %+v but wanted %+v", *gotPtr, *sanitized)
+	}
+}
+
+func Test_Apply_Error(t *testing.T) {
+	type TestBadStruct struct {
+		Name  string `san:"trim"`
+		Field int    `san:"max=no"`
+	}
+
+	s, _ := New()
+
+	orig := TestBadStruct{Name: " a "}
+	_, err := Apply(s, orig)
+	if err == nil || !strings.Contains(err.Error(), "(max)") {
+		t.Errorf("Apply() - got error %v, wanted a max error", err)
+	}
+	if orig.Name != " a " {
+		t.Errorf("Apply() - modified its argument")
+	}
+}
+
+func Test_Apply_SharedPointers(t *testing.T) {
+	s, _ := New()
+
+	child := &testPlanNode{Name: " CHILD "}
+	root := &testPlanNode{Name: " ROOT ", Children: []*testPlanNode{child, child}}
+
+	got, err := Apply(s, root)
+	if err != nil {
+		t.Fatalf("Apply() - got unexpected error %v", err)
+	}
+	if root.Name != " ROOT " || child.Name != " CHILD " {
+		t.Errorf("Apply() - modified its argument")
+	}
+	if got.Name != "root" || got.Children[0].Name != "child" {
+		t.Errorf("Apply() - got %q and %q, wanted root and child", got.Name, got.Children[0].Name)
+	}
+	if got.Children[0] == child || got.Children[0] != got.Children[1] {
+		t.Errorf("Apply() - did not keep the shape of shared pointers")
+	}
+}
+
+func Test_deepCopy_Cyclic(t *testing.T) {
+	root := &testPlanNode{Name: "root"}
+	root.Children = []*testPlanNode{root}
+
+	got := deepCopy(reflect.ValueOf(root)).Interface().(*testPlanNode)
+	if got == root || got.Children[0] != got {
+		t.Errorf("deepCopy() - did not keep the shape of cyclic pointers")
+	}
+}
+
+func Test_Apply_UnexportedFields(t *testing.T) {
+	type inner struct {
+		Name string `san:"trim"`
+	}
+	type TestUnexported struct {
+		inner
+		secret []string
+		Tags   []string `san:"trim"`
+	}
+	type TestUnexportedPtr struct {
+		*inner
+	}
+
+	s, _ := New()
+
+	orig := TestUnexported{
+		inner:  inner{Name: " a "},
+		secret: []string{" b "},
+		Tags:   []string{" c "},
+	}
+	got, err := Apply(s, orig)
+	if err != nil {
+		t.Fatalf("Apply() - got unexpected error %v", err)
+	}
+	if orig.Name != " a " || orig.Tags[0] != " c " {
+		t.Errorf("Apply() - modified its argument, got %+v", orig)
+	}
+	if got.Name != "a" || got.Tags[0] != "c" || got.secret[0] != " b " {
+		t.Errorf("Apply() - got %+v", got)
+	}
+
+	origPtr := TestUnexportedPtr{&inner{Name: " x "}}
+	gotPtr, err := Apply(s, origPtr)
+	if err != nil {
+		t.Fatalf("Apply() - got unexpected error %v", err)
+	}
+	if origPtr.Name != " x " {
+		t.Errorf("Apply() - modified the struct behind an embedded pointer, got %+v", origPtr.inner)
+	}
+	if gotPtr.Name != "x" {
+		t.Errorf("Apply() - got %+v", gotPtr.inner)
+	}
+}
+
+func Test_Slice(t *testing.T) {
+	s, _ := New()
+
+	orig := []testPlanBenchItem{{Name: " A "}, {Name: " B ", Price: 100}}
+	got, err := Slice(s, orig)
+	if err != nil {
+		t.Fatalf("Slice() - got unexpected error %v", err)
+	}
+
+	one := 1
+	want := []testPlanBenchItem{{Name: "a", Price: 0.5, Qty: &one}, {Name: "b", Price: 99.9, Qty: &one}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Slice() - got %+v but wanted %+v", got, want)
+	}
+	if orig[0].Name != " A " || orig[1].Price != 100 {
+		t.Errorf("Slice() - modified its argument, got %+v", orig)
+	}
+}
+
+func Test_Value(t *testing.T) {
+	s, _ := New()
+
+	age, err := Value(s, 200, "min=0,max=150")
+	if err != nil || age != 150 {
+		t.Errorf("Value() - got %d, %v but wanted 150, nil", age, err)
+	}
+
+	orig := []string{" A ", " B ", " C "}
+	tags, err := Value(s, orig, "trim,lower,maxsize=2")
+	if err != nil || !reflect.DeepEqual(tags, []string{"a", "b"}) {
+		t.Errorf("Value() - got %v, %v but wanted [a b], nil", tags, err)
+	}
+	if orig[0] != " A " {
+		t.Errorf("Value() - modified its argument, got %v", orig)
+	}
+
+	if _, err := Value(s, 1, "trim"); err == nil {
+		t.Error("Value() - did not receive expected error")
+	}
+}
+
+func Test_String(t *testing.T) {
+	s, _ := New()
+
+	got, err := String(s, " Borky Borkins ", "trim,lower,max=5")
+	if err != nil || got != "borky" {
+		t.Errorf("String() - got %q, %v but wanted \"borky\", nil", got, err)
+	}
+}
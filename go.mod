@@ -1,3 +1,8 @@
 module github.com/go-sanitize/sanitize
 
-go 1.12
+go 1.18
+
+require (
+	golang.org/x/net v0.35.0
+	golang.org/x/text v0.22.0
+)
@@ -0,0 +1,334 @@
+package sanitize
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// HTMLPolicy is an allow-list of the HTML that the html tag component keeps.
+// Elements that are not allowed are removed, but their text is kept, except
+// for elements such as script and style whose content is not text. Comments
+// and doctypes are always removed.
+type HTMLPolicy struct {
+	// Elements maps every allowed element to the attributes allowed on it.
+	Elements map[string][]string
+	// Attributes are allowed on every allowed element.
+	Attributes []string
+	// URLSchemes are the schemes allowed in attributes holding URLs, such as
+	// href and src. Relative URLs are always allowed.
+	URLSchemes []string
+}
+
+// DefaultHTMLPolicy is the policy used by a bare html tag component, unless
+// another one is registered under the name "default". It allows basic text
+// formatting and links.
+var DefaultHTMLPolicy = HTMLPolicy{
+	Elements: map[string][]string{
+		"a":          {"href", "title"},
+		"b":          nil,
+		"blockquote": {"cite"},
+		"br":         nil,
+		"code":       nil,
+		"em":         nil,
+		"i":          nil,
+		"li":         nil,
+		"ol":         nil,
+		"p":          nil,
+		"pre":        nil,
+		"strong":     nil,
+		"u":          nil,
+		"ul":         nil,
+	},
+	URLSchemes: []string{"http", "https", "mailto"},
+}
+
+// The html tag component values that are modes rather than policy names.
+const (
+	htmlEscape = "escape"
+	htmlStrip  = "strip"
+)
+
+// htmlPolicy is the compiled form of an HTMLPolicy.
+type htmlPolicy struct {
+	elements map[string]map[string]bool
+	schemes  map[string]bool
+}
+
+func compileHTMLPolicy(p HTMLPolicy) *htmlPolicy {
+	c := &htmlPolicy{
+		elements: make(map[string]map[string]bool, len(p.Elements)),
+		schemes:  make(map[string]bool, len(p.URLSchemes)),
+	}
+	for elem, attrs := range p.Elements {
+		allowed := make(map[string]bool, len(attrs)+len(p.Attributes))
+		for _, attr := range attrs {
+			allowed[strings.ToLower(attr)] = true
+		}
+		for _, attr := range p.Attributes {
+			allowed[strings.ToLower(attr)] = true
+		}
+		c.elements[strings.ToLower(elem)] = allowed
+	}
+	for _, scheme := range p.URLSchemes {
+		c.schemes[strings.ToLower(scheme)] = true
+	}
+	return c
+}
+
+// compileHTML parses the "html" tag component: "escape", "strip", or the
+// name of a policy, which defaults to "default".
+func (s *Sanitizer) compileHTML(v string) (func(string) string, error) {
+	switch v {
+	case htmlEscape:
+		return html.EscapeString, nil
+	case htmlStrip:
+		return stripHTML, nil
+	case "":
+		v = "default"
+	}
+
+	p, ok := s.htmlPolicies[v]
+	if !ok && v == "default" {
+		p, ok = defaultHTMLPolicy, true
+	}
+	if !ok {
+		return nil, tagError("html", fmt.Errorf("no HTML policy registered with name %q", v))
+	}
+	return p.sanitize, nil
+}
+
+var defaultHTMLPolicy = compileHTMLPolicy(DefaultHTMLPolicy)
+
+// rawContentElements hold content that is not text meant to be read, so it
+// is removed along with them.
+var rawContentElements = map[atom.Atom]bool{
+	atom.Script:   true,
+	atom.Style:    true,
+	atom.Template: true,
+	atom.Iframe:   true,
+	atom.Noscript: true,
+	atom.Noembed:  true,
+	atom.Noframes: true,
+	atom.Object:   true,
+	atom.Xmp:      true,
+	atom.Title:    true,
+	atom.Textarea: true,
+}
+
+// urlAttributes hold URLs, whose scheme is checked against the policy.
+var urlAttributes = map[string]bool{
+	"href":       true,
+	"src":        true,
+	"cite":       true,
+	"action":     true,
+	"formaction": true,
+	"poster":     true,
+	"background": true,
+	"longdesc":   true,
+	"xlink:href": true,
+}
+
+// sanitize returns s with every element, attribute and URL that p does not
+// allow removed. The result is well formed: tags are balanced and text is
+// escaped.
+func (p *htmlPolicy) sanitize(s string) string {
+	var b strings.Builder
+	var open []string
+	skip := 0 // depth inside raw content elements
+
+	z := html.NewTokenizer(strings.NewReader(s))
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			break
+		}
+		tok := z.Token()
+
+		switch tt {
+		case html.StartTagToken, html.SelfClosingTagToken:
+			if rawContentElements[tok.DataAtom] {
+				if tt == html.StartTagToken && !isVoidElement(tok.DataAtom) {
+					skip++
+				}
+				continue
+			}
+			if skip > 0 {
+				continue
+			}
+			attrs, ok := p.elements[tok.Data]
+			if !ok {
+				continue
+			}
+			tok.Attr = p.attributes(tok.Attr, attrs)
+			if isVoidElement(tok.DataAtom) {
+				tok.Type = html.SelfClosingTagToken
+			} else {
+				tok.Type = html.StartTagToken
+				open = append(open, tok.Data)
+			}
+			b.WriteString(tok.String())
+		case html.EndTagToken:
+			if rawContentElements[tok.DataAtom] {
+				if skip > 0 {
+					skip--
+				}
+				continue
+			}
+			if skip > 0 {
+				continue
+			}
+			// Close the element and any element left open inside it
+			for i := len(open) - 1; i >= 0; i-- {
+				if open[i] == tok.Data {
+					for j := len(open) - 1; j >= i; j-- {
+						b.WriteString("</" + open[j] + ">")
+					}
+					open = open[:i]
+					break
+				}
+			}
+		case html.TextToken:
+			if skip == 0 {
+				b.WriteString(html.EscapeString(tok.Data))
+			}
+		}
+	}
+
+	for i := len(open) - 1; i >= 0; i-- {
+		b.WriteString("</" + open[i] + ">")
+	}
+	return b.String()
+}
+
+// attributes returns the attributes of attrs that are in allowed, dropping
+// URLs whose scheme is not allowed.
+func (p *htmlPolicy) attributes(attrs []html.Attribute, allowed map[string]bool) []html.Attribute {
+	kept := attrs[:0]
+	for _, attr := range attrs {
+		name := attr.Key
+		if attr.Namespace != "" {
+			name = attr.Namespace + ":" + attr.Key
+		}
+		if !allowed[name] {
+			continue
+		}
+		if name == "srcset" && !p.allowedSrcset(attr.Val) {
+			continue
+		}
+		if urlAttributes[name] && !p.allowedURL(attr.Val) {
+			continue
+		}
+		kept = append(kept, attr)
+	}
+	return kept
+}
+
+// allowedURL reports whether the scheme of the URL v is allowed. Browsers
+// ignore tabs and newlines in URLs, so they are removed before the scheme is
+// looked at; URLs that do not parse are not allowed.
+func (p *htmlPolicy) allowedURL(v string) bool {
+	v = strings.Map(func(r rune) rune {
+		if r == '\t' || r == '\n' || r == '\r' {
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(v))
+
+	u, err := url.Parse(v)
+	if err != nil {
+		return false
+	}
+	return u.Scheme == "" || p.schemes[strings.ToLower(u.Scheme)]
+}
+
+// allowedSrcset reports whether the schemes of every URL in the srcset
+// attribute value v are allowed.
+func (p *htmlPolicy) allowedSrcset(v string) bool {
+	for _, candidate := range strings.Split(v, ",") {
+		fields := strings.Fields(candidate)
+		if len(fields) > 0 && !p.allowedURL(fields[0]) {
+			return false
+		}
+	}
+	return true
+}
+
+// stripHTML removes every element from s, along with the content of
+// elements such as script and style, and returns the text that is left.
+// Entities are decoded, so the result is plain text: it must be escaped
+// before it can be put in HTML again.
+func stripHTML(s string) string {
+	var b strings.Builder
+	skip := 0
+
+	// Block elements separate words, so they leave a space behind. It is
+	// only written before the next text, so none is left at either end.
+	pending := false
+	space := func() {
+		pending = b.Len() > 0
+	}
+
+	z := html.NewTokenizer(strings.NewReader(s))
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			break
+		}
+		tok := z.Token()
+
+		switch tt {
+		case html.StartTagToken:
+			if rawContentElements[tok.DataAtom] {
+				skip++
+			} else if skip == 0 && isBlockElement(tok.DataAtom) {
+				space()
+			}
+		case html.SelfClosingTagToken:
+			if skip == 0 && isBlockElement(tok.DataAtom) {
+				space()
+			}
+		case html.EndTagToken:
+			if rawContentElements[tok.DataAtom] {
+				if skip > 0 {
+					skip--
+				}
+			} else if skip == 0 && isBlockElement(tok.DataAtom) {
+				space()
+			}
+		case html.TextToken:
+			if skip == 0 && tok.Data != "" {
+				if pending && !strings.HasPrefix(tok.Data, " ") && !strings.HasSuffix(b.String(), " ") {
+					b.WriteByte(' ')
+				}
+				pending = false
+				b.WriteString(tok.Data)
+			}
+		}
+	}
+	return b.String()
+}
+
+func isVoidElement(a atom.Atom) bool {
+	switch a {
+	case atom.Area, atom.Base, atom.Br, atom.Col, atom.Embed, atom.Hr, atom.Img,
+		atom.Input, atom.Link, atom.Meta, atom.Source, atom.Track, atom.Wbr:
+		return true
+	}
+	return false
+}
+
+func isBlockElement(a atom.Atom) bool {
+	switch a {
+	case atom.Address, atom.Article, atom.Aside, atom.Blockquote, atom.Br, atom.Dd,
+		atom.Div, atom.Dl, atom.Dt, atom.Figcaption, atom.Figure, atom.Footer,
+		atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6, atom.Header, atom.Hr,
+		atom.Li, atom.Main, atom.Nav, atom.Ol, atom.P, atom.Pre, atom.Section,
+		atom.Table, atom.Td, atom.Th, atom.Tr, atom.Ul:
+		return true
+	}
+	return false
+}
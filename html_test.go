@@ -0,0 +1,139 @@
+package sanitize
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_htmlPolicy_sanitize(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "Keeps text as it is.", in: "Q&A (beta)?", want: "Q&amp;A (beta)?"},
+		{name: "Keeps allowed elements.", in: "<p>Hello <b>world</b></p>", want: "<p>Hello <b>world</b></p>"},
+		{name: "Removes other elements but keeps their text.", in: "<div><span>Hi</span></div>", want: "Hi"},
+		{name: "Removes scripts and their content.", in: "a<script>alert(1)</script>b", want: "ab"},
+		{name: "Removes styles and their content.", in: "<style>p{}</style><p>x</p>", want: "<p>x</p>"},
+		{name: "Removes attributes that are not allowed.", in: `<a href="/x" onclick="evil()">x</a>`, want: `<a href="/x">x</a>`},
+		{name: "Removes URLs whose scheme is not allowed.", in: `<a href="javascript:alert(1)">x</a>`, want: `<a>x</a>`},
+		{name: "Removes URLs hidden with entities and tabs.", in: `<a href="java&#x09;script&#58;alert(1)">x</a>`, want: `<a>x</a>`},
+		{name: "Keeps URLs whose scheme is allowed.", in: `<a href="HTTPS://example.com/?a=1&amp;b=2">x</a>`, want: `<a href="HTTPS://example.com/?a=1&amp;b=2">x</a>`},
+		{name: "Closes elements left open.", in: "<p><b>bold", want: "<p><b>bold</b></p>"},
+		{name: "Closes nested elements with their parent.", in: "<p><b>bold</p>after", want: "<p><b>bold</b></p>after"},
+		{name: "Drops end tags that were never opened.", in: "text</b></p>", want: "text"},
+		{name: "Writes void elements as self closing.", in: "a<br>b", want: "a<br/>b"},
+		{name: "Removes comments.", in: "a<!-- <script> -->b", want: "ab"},
+		{name: "Escapes text and attribute values.", in: `<a title="&quot;><script>">1 < 2</a>`, want: `<a title="&#34;&gt;&lt;script&gt;">1 &lt; 2</a>`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := defaultHTMLPolicy.sanitize(tt.in); got != tt.want {
+				t.Errorf("sanitize(%q) - got %q but wanted %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_stripHTML(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "Keeps plain text as it is.", in: "Q&A (beta)?", want: "Q&A (beta)?"},
+		{name: "Removes elements and decodes entities.", in: "<b>Fish &amp; chips</b>", want: "Fish & chips"},
+		{name: "Removes scripts and their content.", in: "a<script>alert(1)</script>b", want: "ab"},
+		{name: "Separates block elements with a space.", in: "<p>one</p><p>two</p>three<br>four", want: "one two three four"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := stripHTML(tt.in); got != tt.want {
+				t.Errorf("stripHTML(%q) - got %q but wanted %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_Sanitize_HTML(t *testing.T) {
+	type TestHTML struct {
+		Default string  `san:"html"`
+		Escaped string  `san:"html=escape"`
+		Plain   *string `san:"html=strip,trim"`
+		Images  string  `san:"html=images"`
+		Capped  string  `san:"max=4,html"`
+	}
+
+	s, err := New(OptionHTMLPolicy{
+		Name: "images",
+		Policy: HTMLPolicy{
+			Elements:   map[string][]string{"img": {"src", "alt"}},
+			Attributes: []string{"class"},
+			URLSchemes: []string{"https"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("New() - got unexpected error %v", err)
+	}
+
+	plain := " <p>Hello</p> "
+	v := &TestHTML{
+		Default: `<p onclick="x()">Hi <img src="a.png"></p>`,
+		Escaped: `<b>"Q&A"</b>`,
+		Plain:   &plain,
+		Images:  `<p class="x"><img class="y" src="http://a/b.png" alt="b"><img src="https://a/c.png" srcset="https://a/c2.png 2x"></p>`,
+		Capped:  "a < b",
+	}
+	if err := s.Sanitize(v); err != nil {
+		t.Fatalf("Sanitize() - got unexpected error %v", err)
+	}
+
+	want := TestHTML{
+		Default: `<p>Hi </p>`,
+		Escaped: `&lt;b&gt;&#34;Q&amp;A&#34;&lt;/b&gt;`,
+		Images:  `<img class="y" alt="b"/><img src="https://a/c.png"/>`,
+		Capped:  "a &lt; ",
+	}
+	if v.Default != want.Default || v.Escaped != want.Escaped || *v.Plain != "Hello" ||
+		v.Images != want.Images || v.Capped != want.Capped {
+		t.Errorf("Sanitize() - got %q, %q, %q, %q and %q", v.Default, v.Escaped, *v.Plain, v.Images, v.Capped)
+	}
+}
+
+func Test_Sanitize_HTMLDefaultPolicy(t *testing.T) {
+	s, _ := New(OptionHTMLPolicy{
+		Name:   "default",
+		Policy: HTMLPolicy{Elements: map[string][]string{"i": nil}},
+	})
+
+	v := "<p><i>only</i> <b>italics</b></p>"
+	if err := s.SanitizeValue(&v, "html"); err != nil {
+		t.Fatalf("SanitizeValue() - got unexpected error %v", err)
+	}
+	if want := "<i>only</i> italics"; v != want {
+		t.Errorf("SanitizeValue() - got %q but wanted %q", v, want)
+	}
+}
+
+func Test_Sanitize_HTMLErrors(t *testing.T) {
+	type TestUnknownPolicy struct {
+		Body string `san:"html=comments"`
+	}
+
+	s, _ := New()
+	err := s.Sanitize(&TestUnknownPolicy{})
+	if err == nil || !strings.Contains(err.Error(), `no HTML policy registered with name "comments"`) {
+		t.Errorf("Sanitize() - got error %v, wanted an unknown policy error", err)
+	}
+
+	for _, options := range [][]Option{
+		{OptionHTMLPolicy{Name: "strip"}},
+		{OptionHTMLPolicy{Name: ""}},
+		{OptionHTMLPolicy{Name: "a"}, OptionHTMLPolicy{Name: "a"}},
+	} {
+		if _, err := New(options...); err == nil {
+			t.Errorf("New() - did not receive expected error for %+v", options)
+		}
+	}
+}
@@ -15,7 +15,7 @@ func Test_sanitizeInt16Field(t *testing.T) {
 		Field int16 `san:"max=41,min=-2"`
 	}
 	type TestInt16StructNegativeMaxTag struct {
-		Field int16 `san:"max=-2,min=42"`
+		Field int16 `san:"max=-2,min=-42"`
 	}
 	type TestInt16StructBadMaxMin struct {
 		Field int16 `san:"max=41,min=42"`
@@ -88,20 +88,20 @@ func Test_sanitizeInt16Field(t *testing.T) {
 			wantErr: false,
 		},
 		{
-			name: "Returns an error if a san:min tag on a int16 field is below 0.",
+			name: "Raises a int16 field to a negative san:min tag.",
 			args: args{
 				v: &TestInt16StructNegativeMinTag{
-					Field: 40,
+					Field: -5,
 				},
 				idx: 0,
 			},
 			want: &TestInt16StructNegativeMinTag{
-				Field: 40,
+				Field: -2,
 			},
-			wantErr: true,
+			wantErr: false,
 		},
 		{
-			name: "Returns an error if a san:max tag on a int16 field is below 0.",
+			name: "Caps a int16 field to a negative san:max tag.",
 			args: args{
 				v: &TestInt16StructNegativeMaxTag{
 					Field: 40,
@@ -109,9 +109,9 @@ func Test_sanitizeInt16Field(t *testing.T) {
 				idx: 0,
 			},
 			want: &TestInt16StructNegativeMaxTag{
-				Field: 40,
+				Field: -2,
 			},
-			wantErr: true,
+			wantErr: false,
 		},
 		{
 			name: "Returns an error if a san:min tag on a int16 field is not numeric.",
@@ -15,7 +15,7 @@ func Test_sanitizeIntField(t *testing.T) {
 		Field int `san:"max=41,min=-2"`
 	}
 	type TestIntStructNegativeMaxTag struct {
-		Field int `san:"max=-2,min=42"`
+		Field int `san:"max=-2,min=-42"`
 	}
 	type TestIntStructBadMaxMin struct {
 		Field int `san:"max=41,min=42"`
@@ -88,20 +88,20 @@ func Test_sanitizeIntField(t *testing.T) {
 			wantErr: false,
 		},
 		{
-			name: "Returns an error if a san:min tag on a int field is below 0.",
+			name: "Raises a int field to a negative san:min tag.",
 			args: args{
 				v: &TestIntStructNegativeMinTag{
-					Field: 40,
+					Field: -5,
 				},
 				idx: 0,
 			},
 			want: &TestIntStructNegativeMinTag{
-				Field: 40,
+				Field: -2,
 			},
-			wantErr: true,
+			wantErr: false,
 		},
 		{
-			name: "Returns an error if a san:max tag on a int field is below 0.",
+			name: "Caps a int field to a negative san:max tag.",
 			args: args{
 				v: &TestIntStructNegativeMaxTag{
 					Field: 40,
@@ -109,9 +109,9 @@ func Test_sanitizeIntField(t *testing.T) {
 				idx: 0,
 			},
 			want: &TestIntStructNegativeMaxTag{
-				Field: 40,
+				Field: -2,
 			},
-			wantErr: true,
+			wantErr: false,
 		},
 		{
 			name: "Returns an error if a san:min tag on a int field is not numeric.",
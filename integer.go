@@ -0,0 +1,124 @@
+package sanitize
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// intOps holds the integer only tag components of a field of type T.
+type intOps[T number] struct {
+	abs      bool
+	multiple T
+	mode     roundingMode
+	nonzero  bool
+	// nonzeroDef replaces zero with the def value, instead of returning an
+	// error.
+	nonzeroDef bool
+}
+
+// compileIntOps parses the integer only tag components of a field of type T.
+// It returns nil if there are none. hasDef tells whether the field has a def
+// value to replace zero with.
+func compileIntOps[T number](tags map[string]string, hasDef bool) (*intOps[T], error) {
+	ops := &intOps[T]{}
+	found := false
+
+	if _, ok := tags["abs"]; ok {
+		ops.abs = true
+		found = true
+	}
+
+	mode, hasMode, err := compileRoundingMode(tags)
+	if err != nil {
+		return nil, err
+	}
+	if v, ok := tags["multiple"]; ok {
+		ops.multiple, err = parseNumber[T](v)
+		if err != nil || ops.multiple <= 0 {
+			return nil, tagError("multiple", fmt.Errorf("multiple must be a whole number above 0, got %q", v))
+		}
+		ops.mode = mode
+		found = true
+	} else if hasMode {
+		for _, name := range roundingDirectionTags {
+			if _, ok := tags[name]; ok {
+				return nil, tagError(name, fmt.Errorf("%s needs a multiple tag component on %s fields", name, reflect.TypeOf(T(0))))
+			}
+		}
+	}
+
+	if v, ok := tags["nonzero"]; ok {
+		switch v {
+		case "", "error":
+		case "def":
+			if !hasDef {
+				return nil, tagError("nonzero", fmt.Errorf("nonzero=def needs a def tag component"))
+			}
+			ops.nonzeroDef = true
+		default:
+			return nil, tagError("nonzero", fmt.Errorf("unknown nonzero action %q, expected error or def", v))
+		}
+		ops.nonzero = true
+		found = true
+	}
+
+	if !found {
+		return nil, nil
+	}
+	return ops, nil
+}
+
+// absNumber returns the absolute value of the integer x. The lowest value of
+// signed types has no positive counterpart, so it becomes the highest one.
+func absNumber[T number](x T) T {
+	if x >= 0 {
+		return x
+	}
+	if x = -x; x < 0 {
+		x = -(x + 1)
+	}
+	return x
+}
+
+// roundMultiple returns the multiple of m closest to the integer x in the
+// direction of mode. If that multiple overflows T, the one on the other
+// side of x is returned instead. T must be an integer type: the remainder is
+// computed with division, which only truncates for integers.
+func roundMultiple[T number](x, m T, mode roundingMode) T {
+	q := x / m
+	n := q * m
+	r := x - n
+	if r == 0 {
+		return x
+	}
+
+	// r has the sign of x, so the multiple away from zero is n+m for
+	// positive values and n-m for negative ones
+	away := r > 0
+	switch mode {
+	case roundFloor:
+		if r > 0 {
+			return n
+		}
+	case roundCeil:
+		if r < 0 {
+			return n
+		}
+	case roundTrunc:
+		return n
+	case roundHalfUp, roundHalfEven:
+		abs := absNumber(r)
+		if abs < m-abs || abs == m-abs && mode == roundHalfEven && q-q/2*2 == 0 {
+			return n
+		}
+	}
+
+	if away {
+		if up := n + m; up > n {
+			return up
+		}
+	} else if down := n - m; down < n {
+		return down
+	}
+	return n
+}
@@ -0,0 +1,124 @@
+package sanitize
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func Test_roundMultiple(t *testing.T) {
+	tests := []struct {
+		name string
+		x, m int64
+		mode roundingMode
+		want int64
+	}{
+		{name: "Keeps multiples.", x: 10, m: 5, mode: roundHalfUp, want: 10},
+		{name: "Rounds down to the nearest multiple.", x: 12, m: 5, mode: roundHalfUp, want: 10},
+		{name: "Rounds up to the nearest multiple.", x: 13, m: 5, mode: roundHalfUp, want: 15},
+		{name: "Rounds halves away from zero.", x: 15, m: 10, mode: roundHalfUp, want: 20},
+		{name: "Rounds negative halves away from zero.", x: -15, m: 10, mode: roundHalfUp, want: -20},
+		{name: "Rounds halves to the even multiple.", x: 25, m: 10, mode: roundHalfEven, want: 20},
+		{name: "Rounds odd halves away from zero.", x: 35, m: 10, mode: roundHalfEven, want: 40},
+		{name: "floor rounds down.", x: 19, m: 10, mode: roundFloor, want: 10},
+		{name: "floor rounds negatives down.", x: -11, m: 10, mode: roundFloor, want: -20},
+		{name: "ceil rounds up.", x: 11, m: 10, mode: roundCeil, want: 20},
+		{name: "ceil rounds negatives up.", x: -19, m: 10, mode: roundCeil, want: -10},
+		{name: "trunc rounds towards zero.", x: -19, m: 10, mode: roundTrunc, want: -10},
+		{name: "Rounds small values to zero.", x: 2, m: 5, mode: roundHalfUp, want: 0},
+		{name: "Steps back on overflow.", x: math.MaxInt64, m: 10, mode: roundCeil, want: math.MaxInt64 - 7},
+		{name: "Steps back on underflow.", x: math.MinInt64, m: 10, mode: roundFloor, want: math.MinInt64 + 8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := roundMultiple(tt.x, tt.m, tt.mode); got != tt.want {
+				t.Errorf("roundMultiple(%d, %d) - got %d but wanted %d", tt.x, tt.m, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_absNumber(t *testing.T) {
+	if got := absNumber(int8(-5)); got != 5 {
+		t.Errorf("absNumber(-5) - got %d", got)
+	}
+	if got := absNumber(int8(math.MinInt8)); got != math.MaxInt8 {
+		t.Errorf("absNumber(MinInt8) - got %d", got)
+	}
+	if got := absNumber(uint8(200)); got != 200 {
+		t.Errorf("absNumber(200) - got %d", got)
+	}
+}
+
+func Test_Sanitize_IntegerTags(t *testing.T) {
+	type TestIntegers struct {
+		Qty      int     `san:"abs,max=100"`
+		PageSize uint16  `san:"multiple=10,ceil,max=100"`
+		Offset   int64   `san:"multiple=25,floor"`
+		Limit    *int32  `san:"nonzero=def,def=20"`
+		Step     int8    `san:"multiple=5,nonzero=def,def=5"`
+		Required uint    `san:"nonzero"`
+		Signed   []int16 `san:"abs,multiple=2,rounding=halfeven"`
+		Capped   int     `san:"max=13,multiple=5"`
+		Raised   int     `san:"min=12,multiple=5,floor"`
+		Narrow   int     `san:"min=11,max=13,multiple=5"`
+	}
+
+	s, _ := New()
+
+	zero := int32(0)
+	v := &TestIntegers{
+		Qty:      -150,
+		PageSize: 91,
+		Offset:   -1,
+		Limit:    &zero,
+		Step:     2,
+		Required: 3,
+		Signed:   []int16{-3, 5, 6},
+		Capped:   100,
+	}
+	if err := s.Sanitize(v); err != nil {
+		t.Fatalf("Sanitize() - got unexpected error %v", err)
+	}
+	if v.Qty != 100 || v.PageSize != 100 || v.Offset != -25 || *v.Limit != 20 || v.Step != 5 || v.Required != 3 {
+		t.Errorf("Sanitize() - got %+v (limit %d)", v, *v.Limit)
+	}
+	if v.Signed[0] != 4 || v.Signed[1] != 4 || v.Signed[2] != 6 {
+		t.Errorf("Sanitize() - got %v, wanted [4 4 6]", v.Signed)
+	}
+	// Multiples do not leave the range of min and max
+	if v.Capped != 10 || v.Raised != 15 || v.Narrow != 11 {
+		t.Errorf("Sanitize() - got %d, %d and %d, wanted 10, 15 and 11", v.Capped, v.Raised, v.Narrow)
+	}
+
+	v = &TestIntegers{Limit: &zero, Step: 5}
+	err := s.Sanitize(v)
+	if err == nil || err.Error() != `field "Required" (nonzero): 0 is not allowed` {
+		t.Errorf("Sanitize() - got error %v, wanted a nonzero error", err)
+	}
+}
+
+func Test_compileIntOps_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		tags    map[string]string
+		hasDef  bool
+		wantErr string
+	}{
+		{name: "Rejects a zero multiple.", tags: map[string]string{"multiple": "0"}, wantErr: "multiple must be a whole number above 0"},
+		{name: "Rejects a negative multiple.", tags: map[string]string{"multiple": "-5"}, wantErr: "multiple must be a whole number above 0"},
+		{name: "Rejects a fractional multiple.", tags: map[string]string{"multiple": "2.5"}, wantErr: "multiple must be a whole number above 0"},
+		{name: "Rejects a direction without multiple.", tags: map[string]string{"floor": ""}, wantErr: "floor needs a multiple tag component on int fields"},
+		{name: "Rejects two directions.", tags: map[string]string{"multiple": "5", "floor": "", "ceil": ""}, wantErr: "conflicting tag components floor and ceil"},
+		{name: "Rejects nonzero=def without a def value.", tags: map[string]string{"nonzero": "def"}, wantErr: "nonzero=def needs a def tag component"},
+		{name: "Rejects unknown nonzero actions.", tags: map[string]string{"nonzero": "one"}, hasDef: true, wantErr: `unknown nonzero action "one"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := compileIntOps[int](tt.tags, tt.hasDef)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("compileIntOps() - got error %v, wanted it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package sanitize
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// mapKeysPlan compiles the "keys" component of a map field into the plan for
+// the keys of the map. The component holds other components separated by "|",
+// e.g. keys=trim|lower.
+func (c *planCompiler) mapKeysPlan(t reflect.Type, tags map[string]string) (*valuePlan, error) {
+	keys, ok := tags["keys"]
+	if !ok {
+		return nil, nil
+	}
+
+	comps, err := parseTag(keys, '|')
+	if err != nil {
+		return nil, tagError("keys", err)
+	}
+	keyTags := tagComponents(comps)
+	order := tagNames(comps)
+	if c.strict {
+		if err := c.checkTags(t, keyTags); err != nil {
+			return nil, tagError("keys", err)
+		}
+	}
+
+	plan, err := c.valuePlan(t, keyTags, order, nil)
+	if err != nil {
+		return nil, tagError("keys", err)
+	}
+	return plan, nil
+}
+
+// mapEntry is a map entry once sanitized, before it is written back.
+type mapEntry struct {
+	key    reflect.Value
+	newKey reflect.Value
+	value  reflect.Value
+}
+
+// applyMap sanitizes the keys and values of the map v. Map values can not be
+// set in place, so each one is copied, sanitized and written back. Keys are
+// only renamed when no two of them end up being equal, so that no value is
+// silently lost. Values are sanitized with the components of phase, and keys
+// only in the phase of the keys component.
+func (p *valuePlan) applyMap(w *walker, v reflect.Value, phase int) error {
+	if v.Len() == 0 {
+		return nil
+	}
+
+	entries := sortedMapEntries(v)
+	for i := range entries {
+		e := &entries[i]
+		k := e.key
+
+		w.push(pathSegment{key: k})
+		if p.elem != nil {
+			value := reflect.New(e.value.Type()).Elem()
+			value.Set(e.value)
+			if err := p.elem.applyPhase(w, value, phase); err != nil {
+				return err
+			}
+			e.value = value
+		}
+		if p.key != nil && inPhase(phase, p.phase) {
+			newKey := reflect.New(k.Type()).Elem()
+			newKey.Set(k)
+			if err := p.key.apply(w, newKey); err != nil {
+				return err
+			}
+			e.newKey = newKey
+		}
+		w.pop()
+	}
+
+	var collision error
+	renamed := make(map[interface{}]reflect.Value, len(entries))
+	for _, e := range entries {
+		if other, ok := renamed[e.newKey.Interface()]; ok {
+			collision = fmt.Errorf(
+				"keys %v and %v are both sanitized to %v",
+				other.Interface(),
+				e.key.Interface(),
+				e.newKey.Interface(),
+			)
+			break
+		}
+		renamed[e.newKey.Interface()] = e.key
+	}
+
+	// Renamed keys are all removed before anything is written, since a new
+	// key may be the old key of another entry
+	for _, e := range entries {
+		if collision == nil && e.newKey.Interface() != e.key.Interface() {
+			v.SetMapIndex(e.key, reflect.Value{})
+		}
+	}
+	for _, e := range entries {
+		if collision == nil {
+			v.SetMapIndex(e.newKey, e.value)
+		} else {
+			v.SetMapIndex(e.key, e.value)
+		}
+	}
+
+	if collision != nil {
+		return w.fail("keys", collision)
+	}
+	return nil
+}
+
+// sortedMapEntries returns the entries of the map v, sorted by key when the
+// keys are strings or numbers so that maps are always walked in the same
+// order. Keys that are not equal to themselves, such as NaN, are left out:
+// their entries can be neither looked up nor replaced, so writing them back
+// would only add new ones.
+func sortedMapEntries(v reflect.Value) []mapEntry {
+	entries := make([]mapEntry, 0, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		k := iter.Key()
+		if k.Interface() != k.Interface() {
+			continue
+		}
+		entries = append(entries, mapEntry{key: k, newKey: k, value: iter.Value()})
+	}
+
+	var less func(a, b reflect.Value) bool
+	switch v.Type().Key().Kind() {
+	case reflect.String:
+		less = func(a, b reflect.Value) bool { return a.String() < b.String() }
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		less = func(a, b reflect.Value) bool { return a.Int() < b.Int() }
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		less = func(a, b reflect.Value) bool { return a.Uint() < b.Uint() }
+	case reflect.Float32, reflect.Float64:
+		less = func(a, b reflect.Value) bool { return a.Float() < b.Float() }
+	default:
+		return entries
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return less(entries[i].key, entries[j].key)
+	})
+	return entries
+}
@@ -0,0 +1,250 @@
+package sanitize
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_Sanitize_Map(t *testing.T) {
+	type Address struct {
+		City string `san:"trim,title"`
+		Zip  *int   `san:"min=1000,def=1000"`
+	}
+	type TestStruct struct {
+		Labels      map[string]string    `san:"trim,lower,keys=trim|lower"`
+		PtrValues   map[string]*string   `san:"trim,def=none"`
+		SliceValues map[string][]string  `san:"maxsize=2,upper"`
+		IntValues   map[int]int          `san:"max=10"`
+		Addresses   map[string]Address   `san:"keys=lower"`
+		AddressPtrs map[string]*Address  `san:"keys=max=3"`
+		PtrMap      *map[string]string   `san:"trim"`
+		Untagged    map[string]string    `san:""`
+		NilMap      map[string]string    `san:"trim"`
+		Nested      map[string]TestLabel `san:""`
+	}
+
+	s, _ := New()
+
+	argPtr := " value "
+	resPtr := "value"
+	resPtrDef := "none"
+	zip := 20
+	resZip := 1000
+	resZipDef := 1000
+
+	v := &TestStruct{
+		Labels: map[string]string{
+			" Env ": " PROD ",
+			"team":  " Core ",
+		},
+		PtrValues: map[string]*string{
+			"set":   &argPtr,
+			"unset": nil,
+		},
+		SliceValues: map[string][]string{
+			"a": {"x", "y", "z"},
+		},
+		IntValues: map[int]int{1: 5, 2: 50},
+		Addresses: map[string]Address{
+			"HOME": {City: " new york ", Zip: &zip},
+		},
+		AddressPtrs: map[string]*Address{
+			"office": {City: " paris "},
+		},
+		PtrMap:   &map[string]string{"k": " v "},
+		Untagged: map[string]string{" k ": " v "},
+		Nested: map[string]TestLabel{
+			"n": {Name: " NESTED "},
+		},
+	}
+	want := &TestStruct{
+		Labels: map[string]string{
+			"env":  "prod",
+			"team": "core",
+		},
+		PtrValues: map[string]*string{
+			"set":   &resPtr,
+			"unset": &resPtrDef,
+		},
+		SliceValues: map[string][]string{
+			"a": {"X", "Y"},
+		},
+		IntValues: map[int]int{1: 5, 2: 10},
+		Addresses: map[string]Address{
+			"home": {City: "New York", Zip: &resZip},
+		},
+		AddressPtrs: map[string]*Address{
+			"off": {City: "Paris", Zip: &resZipDef},
+		},
+		PtrMap:   &map[string]string{"k": "v"},
+		Untagged: map[string]string{" k ": " v "},
+		Nested: map[string]TestLabel{
+			"n": {Name: "nested"},
+		},
+	}
+
+	if err := s.Sanitize(v); err != nil {
+		t.Fatalf("Sanitize() - got unexpected error %v", err)
+	}
+	if !reflect.DeepEqual(v, want) {
+		t.Errorf("Sanitize() - got %+v but wanted %+v", v, want)
+	}
+}
+
+type TestLabel struct {
+	Name string `san:"trim,lower"`
+}
+
+func Test_Sanitize_MapKeyRename(t *testing.T) {
+	type TestStruct struct {
+		Labels map[string]string `san:"keys=trim=x"`
+	}
+
+	s, _ := New()
+
+	// "xax" becomes "a", which is already a key
+	v := &TestStruct{
+		Labels: map[string]string{
+			"a":   "1",
+			"xax": "2",
+			"xbx": "3",
+		},
+	}
+	want := &TestStruct{
+		Labels: map[string]string{
+			"a": "2",
+			"b": "3",
+		},
+	}
+
+	if err := s.Sanitize(v); err == nil {
+		t.Fatal("Sanitize() - did not receive expected error")
+	}
+
+	v.Labels = map[string]string{
+		"xax": "2",
+		"xbx": "3",
+	}
+	if err := s.Sanitize(v); err != nil {
+		t.Fatalf("Sanitize() - got unexpected error %v", err)
+	}
+	if !reflect.DeepEqual(v, want) {
+		t.Errorf("Sanitize() - got %+v but wanted %+v", v, want)
+	}
+}
+
+func Test_Sanitize_MapKeyCollision(t *testing.T) {
+	type TestStruct struct {
+		Labels map[string]string `san:"trim,keys=lower"`
+	}
+
+	s, _ := New()
+
+	v := &TestStruct{
+		Labels: map[string]string{
+			"Env": " a ",
+			"env": " b ",
+		},
+	}
+	want := &TestStruct{
+		Labels: map[string]string{
+			"Env": "a",
+			"env": "b",
+		},
+	}
+
+	err := s.Sanitize(v)
+
+	var fe *FieldError
+	if !errors.As(err, &fe) {
+		t.Fatalf("Sanitize() - got error %v, wanted a *FieldError", err)
+	}
+	if fe.Path != "Labels" || fe.Tag != "keys" {
+		t.Errorf("Sanitize() - got error on %q (%s), wanted it on Labels (keys)", fe.Path, fe.Tag)
+	}
+	if !reflect.DeepEqual(v, want) {
+		t.Errorf("Sanitize() - got %+v but wanted %+v", v, want)
+	}
+}
+
+func Test_Sanitize_MapNaNKey(t *testing.T) {
+	type TestStruct struct {
+		Scores map[float64]string `san:"trim,keys=max=10"`
+	}
+
+	s, _ := New()
+
+	v := &TestStruct{
+		Scores: map[float64]string{
+			math.NaN(): " nan ",
+			20:         " b ",
+		},
+	}
+
+	// NaN keys are left alone rather than added again on every call
+	for i := 0; i < 2; i++ {
+		if err := s.Sanitize(v); err != nil {
+			t.Fatalf("Sanitize() - got unexpected error %v", err)
+		}
+	}
+	if len(v.Scores) != 2 || v.Scores[10] != "b" {
+		t.Errorf("Sanitize() - got %+v", v)
+	}
+	for k, value := range v.Scores {
+		if math.IsNaN(k) && value != " nan " {
+			t.Errorf("Sanitize() - got %q for the NaN key", value)
+		}
+	}
+}
+
+func Test_Sanitize_MapErrorPath(t *testing.T) {
+	type TestStruct struct {
+		Items map[string]testErrorsItem
+	}
+
+	s, _ := New(OptionSanitizerFunc{Name: "positive", Sanitizer: positive})
+
+	v := &TestStruct{
+		Items: map[string]testErrorsItem{
+			"ok":  {Price: 1},
+			"bad": {Price: -1},
+		},
+	}
+
+	err := s.Sanitize(v)
+
+	var fe *FieldError
+	if !errors.As(err, &fe) {
+		t.Fatalf("Sanitize() - got error %v, wanted a *FieldError", err)
+	}
+	if want := `Items["bad"].Price`; fe.Path != want {
+		t.Errorf("FieldError.Path = %q, want %q", fe.Path, want)
+	}
+}
+
+func Test_Validate_Map(t *testing.T) {
+	type TestGood struct {
+		Labels map[string]string `san:"trim,keys=trim|lower|max=10"`
+	}
+	type TestBadKeys struct {
+		Labels map[string]string `san:"keys=trim|shout"`
+	}
+	type TestBadKeyValue struct {
+		Labels map[string]string `san:"keys=max=abc"`
+	}
+
+	s, _ := New()
+
+	if err := s.Validate(TestGood{}); err != nil {
+		t.Errorf("Validate() - got unexpected error %v", err)
+	}
+	if err := s.Validate(TestBadKeys{}); err == nil || !strings.Contains(err.Error(), `unknown tag component "shout"`) {
+		t.Errorf("Validate() - got error %v, wanted an unknown component error", err)
+	}
+	if err := s.Validate(TestBadKeyValue{}); err == nil || !strings.Contains(err.Error(), "(keys)") {
+		t.Errorf("Validate() - got error %v, wanted a keys error", err)
+	}
+}
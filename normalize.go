@@ -0,0 +1,64 @@
+package sanitize
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// normForms are the Unicode normalization forms, by tag component.
+var normForms = map[string]norm.Form{
+	"nfc":  norm.NFC,
+	"nfd":  norm.NFD,
+	"nfkc": norm.NFKC,
+	"nfkd": norm.NFKD,
+}
+
+// asciiFolds holds the letters that have no decomposition, and so keep no
+// base letter once their marks are stripped, but do have a usual ASCII
+// spelling.
+var asciiFolds = map[rune]string{
+	'ß': "ss", 'ẞ': "SS",
+	'æ': "ae", 'Æ': "AE",
+	'œ': "oe", 'Œ': "OE",
+	'ø': "o", 'Ø': "O",
+	'đ': "d", 'Đ': "D",
+	'ð': "d", 'Ð': "D",
+	'ł': "l", 'Ł': "L",
+	'þ': "th", 'Þ': "Th",
+	'ı': "i",
+}
+
+// asciiFold strips diacritics from s (é becomes e), replaces compatibility
+// characters with their usual form (ﬁ becomes fi, Ａ becomes A) and spells
+// letters such as ß and ø in ASCII. Characters with no ASCII equivalent are
+// kept, in NFC form. Meant for Latin text: in scripts that write vowels with
+// combining marks, stripping them changes the text.
+func asciiFold(s string) string {
+	ascii := true
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			ascii = false
+			break
+		}
+	}
+	if ascii {
+		return s
+	}
+
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range norm.NFKD.String(s) {
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+		if fold, ok := asciiFolds[r]; ok {
+			b.WriteString(fold)
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return norm.NFC.String(b.String())
+}
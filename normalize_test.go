@@ -0,0 +1,74 @@
+package sanitize
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_asciiFold(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "plain ascii", want: "plain ascii"},
+		{in: "Crème brûlée", want: "Creme brulee"},
+		{in: "Crème", want: "Creme"},
+		{in: "Straße", want: "Strasse"},
+		{in: "Øresund Łódź Þórr", want: "Oresund Lodz Thorr"},
+		{in: "ﬁne Ａ１", want: "fine A1"},
+		{in: "naïve 日本 한국", want: "naive 日本 한국"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			if got := asciiFold(tt.in); got != tt.want {
+				t.Errorf("asciiFold(%q) - got %q but wanted %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_Sanitize_Normalization(t *testing.T) {
+	type TestNorm struct {
+		NFC      string  `san:"nfc"`
+		NFD      string  `san:"nfd"`
+		NFKC     *string `san:"nfkc"`
+		NFKD     string  `san:"nfkd"`
+		Username string  `san:"trim,nfc,max=4,lower"`
+		Slug     string  `san:"ascii,lower"`
+	}
+
+	s, _ := New()
+
+	nfkc := "ﬁ①"
+	v := &TestNorm{
+		NFC:      "Café",
+		NFD:      "Café",
+		NFKC:     &nfkc,
+		NFKD:     "ﬁé",
+		Username: "  Cafés  ",
+		Slug:     "Über Straße",
+	}
+	if err := s.Sanitize(v); err != nil {
+		t.Fatalf("Sanitize() - got unexpected error %v", err)
+	}
+
+	want := TestNorm{
+		NFC:      "Café",
+		NFD:      "Café",
+		NFKD:     "fié",
+		Username: "café",
+		Slug:     "uber strasse",
+	}
+	if v.NFC != want.NFC || v.NFD != want.NFD || *v.NFKC != "fi1" || v.NFKD != want.NFKD ||
+		v.Username != want.Username || v.Slug != want.Slug {
+		t.Errorf("Sanitize() - got %+v (nfkc %q)", *v, *v.NFKC)
+	}
+
+	type TestNormConflict struct {
+		Name string `san:"nfc,nfkd"`
+	}
+	err := s.Sanitize(&TestNormConflict{})
+	if err == nil || !strings.Contains(err.Error(), "conflicting tag components nfc and nfkd") {
+		t.Errorf("Sanitize() - got error %v, wanted a conflict error", err)
+	}
+}
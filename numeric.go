@@ -0,0 +1,249 @@
+package sanitize
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// number is the set of types handled by the numeric sanitizer. Defined types
+// are dispatched on their kind, so a single instantiation per kind covers
+// them too.
+type number interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 |
+		~float32 | ~float64
+}
+
+// The field sanitizers of every numeric type.
+var (
+	sanitizeIntField     = sanitizeNumberField[int]
+	sanitizeInt8Field    = sanitizeNumberField[int8]
+	sanitizeInt16Field   = sanitizeNumberField[int16]
+	sanitizeInt32Field   = sanitizeNumberField[int32]
+	sanitizeInt64Field   = sanitizeNumberField[int64]
+	sanitizeUintField    = sanitizeNumberField[uint]
+	sanitizeUint8Field   = sanitizeNumberField[uint8]
+	sanitizeUint16Field  = sanitizeNumberField[uint16]
+	sanitizeUint32Field  = sanitizeNumberField[uint32]
+	sanitizeUint64Field  = sanitizeNumberField[uint64]
+	sanitizeFloat32Field = sanitizeNumberField[float32]
+	sanitizeFloat64Field = sanitizeNumberField[float64]
+)
+
+// sanitizeNumberField sanitizes a numeric field of type T. Requires the whole
+// reflect.Value for the struct because it needs access to both the Value and
+// Type of the struct.
+func sanitizeNumberField[T number](s Sanitizer, structValue reflect.Value, idx int) error {
+	return s.sanitizeScalarField(structValue, idx, compileNumberRule[T])
+}
+
+// compileNumberRule parses the tag components of a numeric field of type T
+// once, so the returned rule only has to compare and set values.
+func compileNumberRule[T number](s *Sanitizer, t reflect.Type, tags map[string]string, order []string) (*scalarRule, error) {
+	var err error
+	numType := reflect.TypeOf(T(0))
+	tags = s.builtinTags(tags)
+
+	// Unsigned fields can not hold negative values. strconv would only report
+	// them as invalid syntax, so they get a clearer error here.
+	parse := func(name string) (T, error) {
+		v := tags[name]
+		if numType.Kind() >= reflect.Uint && numType.Kind() <= reflect.Uint64 && strings.HasPrefix(v, "-") {
+			return 0, tagError(name, fmt.Errorf("%s on %s field can not be below 0", name, numType))
+		}
+		n, err := parseNumber[T](v)
+		if err != nil {
+			return 0, tagError(name, err)
+		}
+		return n, nil
+	}
+
+	// Minimum value
+	_, hasMin := tags["min"]
+	min := T(0)
+	if hasMin {
+		min, err = parse("min")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Maximum value
+	_, hasMax := tags["max"]
+	max := T(0)
+	if hasMax {
+		max, err = parse("max")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Checking if minimum is not higher than maximum
+	if hasMax && hasMin && max < min {
+		return nil, tagError("max", fmt.Errorf(
+			"max (%+v) less than min (%+v) on %s field",
+			max,
+			min,
+			numType,
+		))
+	}
+
+	// Default value
+	_, hasDef := tags["def"]
+	def := T(0)
+	if hasDef {
+		def, err = parse("def")
+		if err != nil {
+			return nil, err
+		}
+
+		// Making sure default is not smaller than min or higher than max
+		if hasMax && def > max {
+			return nil, tagError("def", fmt.Errorf(
+				"incompatible def and max tag components, def (%+v) is "+
+					"higher than max (%+v)",
+				def,
+				max,
+			))
+		}
+		if hasMin && def < min {
+			return nil, tagError("def", fmt.Errorf(
+				"incompatible def and min tag components, def (%+v) is "+
+					"lower than min (%+v)",
+				def,
+				min,
+			))
+		}
+	}
+
+	// NaN and infinity checks and rounding, only for floats
+	var check *floatCheck
+	var rounder *floatRounder
+	if k := numType.Kind(); k == reflect.Float32 || k == reflect.Float64 {
+		check, err = compileFloatCheck(tags, hasDef)
+		if err != nil {
+			return nil, err
+		}
+		rounder, err = compileFloatRounding(tags, numType.Bits())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Integer only components
+	var ints *intOps[T]
+	if k := numType.Kind(); k != reflect.Float32 && k != reflect.Float64 {
+		ints, err = compileIntOps[T](tags, hasDef)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	rule := &scalarRule{}
+
+	// withinBounds rounds x, which is already within min and max, with
+	// round. When the result crosses one of the bounds, x is rounded towards
+	// the inside of the range instead, and kept as it is if the range holds
+	// no rounded value at all.
+	withinBounds := func(x T, mode roundingMode, round func(x T, mode roundingMode) T) T {
+		n := round(x, mode)
+		switch {
+		case hasMax && n > max:
+			n = round(x, roundFloor)
+		case hasMin && n < min:
+			n = round(x, roundCeil)
+		default:
+			return n
+		}
+		if hasMin && n < min || hasMax && n > max {
+			return x
+		}
+		return n
+	}
+
+	// Replace or reject NaN and infinities, take the absolute value of
+	// integers, apply min and max transforms, round the result without
+	// leaving the range, and then replace or reject zero integers. They all
+	// run together, in the phase of the first one written.
+	if hasMin || hasMax || check != nil || rounder != nil || ints != nil {
+		phase := s.firstPhase(order, tags)
+		rule.apply = func(field reflect.Value, p int) error {
+			if !inPhase(p, phase) {
+				return nil
+			}
+			if check != nil {
+				if x := float64(numberOf[T](field)); check.catches(x) {
+					switch check.action {
+					case nonFiniteError:
+						return check.err(x)
+					case nonFiniteZero:
+						setNumber(field, T(0))
+					case nonFiniteDef:
+						setNumber(field, def)
+					}
+				}
+			}
+			if ints != nil && ints.abs {
+				setNumber(field, absNumber(numberOf[T](field)))
+			}
+			if hasMin && min > numberOf[T](field) {
+				setNumber(field, min)
+			}
+			if hasMax && max < numberOf[T](field) {
+				setNumber(field, max)
+			}
+			if rounder != nil {
+				setNumber(field, withinBounds(numberOf[T](field), rounder.mode, func(x T, mode roundingMode) T {
+					return T(rounder.roundMode(float64(x), mode))
+				}))
+			}
+			if ints != nil && ints.multiple > 0 {
+				setNumber(field, withinBounds(numberOf[T](field), ints.mode, func(x T, mode roundingMode) T {
+					return roundMultiple(x, ints.multiple, mode)
+				}))
+			}
+			if ints != nil && ints.nonzero && numberOf[T](field) == 0 {
+				if !ints.nonzeroDef {
+					return tagError("nonzero", fmt.Errorf("0 is not allowed"))
+				}
+				setNumber(field, def)
+			}
+			return nil
+		}
+	}
+
+	if hasDef {
+		rule.def = func(field reflect.Value) error {
+			setNumber(field, def)
+			return nil
+		}
+	}
+
+	return rule, nil
+}
+
+// numberOf returns the value held by field, which must be of a kind that
+// matches T.
+func numberOf[T number](field reflect.Value) T {
+	switch field.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return T(field.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return T(field.Uint())
+	default:
+		return T(field.Float())
+	}
+}
+
+// setNumber sets field, which must be of a kind that matches T, to n.
+func setNumber[T number](field reflect.Value, n T) {
+	switch field.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		field.SetInt(int64(n))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		field.SetUint(uint64(n))
+	default:
+		field.SetFloat(float64(n))
+	}
+}
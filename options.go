@@ -44,10 +44,112 @@ func (o OptionDateFormat) value() interface{} {
 	return o
 }
 
-// OptionSanitizerFunc allows users to use custom sanitizer functions
+// OptionCollectErrors allows users to keep sanitizing the remaining fields
+// after an error, and get every error back in a MultiError instead of only
+// the first one. Fields with a bad tag are reported every time they are
+// reached, and the other fields are still sanitized
+type OptionCollectErrors struct {
+	Value bool
+}
+
+var _ Option = OptionCollectErrors{}
+
+const optionCollectErrorsID = "collect-errors"
+
+func (o OptionCollectErrors) id() string {
+	return optionCollectErrorsID
+}
+
+func (o OptionCollectErrors) value() interface{} {
+	return o.Value
+}
+
+// OptionDefZero allows users to apply the def tag component of fields that
+// hold their zero value, and not only of nil pointers, as if every field with
+// a def also had the defzero tag component
+type OptionDefZero struct {
+	Value bool
+}
+
+var _ Option = OptionDefZero{}
+
+const optionDefZeroID = "def-zero"
+
+func (o OptionDefZero) id() string {
+	return optionDefZeroID
+}
+
+func (o OptionDefZero) value() interface{} {
+	return o.Value
+}
+
+// OptionTagOrder allows users to apply the components of a tag in the order
+// they are written, instead of the fixed order of precedence. Custom
+// sanitizers also run at their place in the tag
+type OptionTagOrder struct {
+	Value bool
+}
+
+var _ Option = OptionTagOrder{}
+
+const optionTagOrderID = "tag-order"
+
+func (o OptionTagOrder) id() string {
+	return optionTagOrderID
+}
+
+func (o OptionTagOrder) value() interface{} {
+	return o.Value
+}
+
+// OptionDisableTags allows users to turn off built-in tag components. Tags
+// that use them are reported as errors
+type OptionDisableTags struct {
+	Names []string
+}
+
+var _ Option = OptionDisableTags{}
+
+const optionDisableTagsID = "disable-tags"
+
+func (o OptionDisableTags) id() string {
+	return optionDisableTagsID
+}
+
+func (o OptionDisableTags) value() interface{} {
+	return o.Names
+}
+
+// OptionHTMLPolicy allows users to register an HTMLPolicy under a name, so
+// that fields can use it with the html=<name> tag component. Registering a
+// policy named "default" replaces DefaultHTMLPolicy for bare html tags
+type OptionHTMLPolicy struct {
+	Name   string
+	Policy HTMLPolicy
+}
+
+var _ Option = OptionHTMLPolicy{}
+
+const optionHTMLPolicyID = "html-policy"
+
+func (o OptionHTMLPolicy) id() string {
+	return optionHTMLPolicyID
+}
+
+func (o OptionHTMLPolicy) value() interface{} {
+	return o
+}
+
+// OptionSanitizerFunc allows users to use custom sanitizer functions. Custom
+// sanitizers run in the order they are written in the tag, before the
+// built-in components unless After is set. With OptionTagOrder, they run at
+// their place in the tag and After is ignored. Set Override to replace the
+// built-in tag component with the same name, rather than running along with it
 type OptionSanitizerFunc struct {
 	Name      string
 	Sanitizer SanitizerFunc
+	After     bool
+	Override  bool
 }
 
 var _ Option = OptionSanitizerFunc{}
@@ -61,3 +163,115 @@ func (o OptionSanitizerFunc) id() string {
 func (o OptionSanitizerFunc) value() interface{} {
 	return o.Sanitizer
 }
+
+// OptionStringSanitizer allows users to register a custom sanitizer of
+// string values. Func is called with the value and with the value of the tag
+// component, e.g. "x" for slug=x. Unlike with OptionSanitizerFunc, pointers,
+// slices, arrays, maps and defaults are handled like for the built-in
+// components, and Func runs before them unless After is set. With
+// OptionTagOrder, Func runs at its place in the tag and After is ignored. Set
+// Override to replace the built-in tag component with the same name, rather
+// than running along with it
+type OptionStringSanitizer struct {
+	Name     string
+	Func     func(v string, param string) (string, error)
+	After    bool
+	Override bool
+}
+
+var _ Option = OptionStringSanitizer{}
+
+const optionStringSanitizerID = "string-sanitizer"
+
+func (o OptionStringSanitizer) id() string {
+	return optionStringSanitizerID
+}
+
+func (o OptionStringSanitizer) value() interface{} {
+	return o
+}
+
+// OptionIntSanitizer allows users to register a custom sanitizer of signed
+// integer values, of any size. See OptionStringSanitizer. An error is
+// returned if the result of Func does not fit in the field
+type OptionIntSanitizer struct {
+	Name     string
+	Func     func(v int64, param string) (int64, error)
+	After    bool
+	Override bool
+}
+
+var _ Option = OptionIntSanitizer{}
+
+const optionIntSanitizerID = "int-sanitizer"
+
+func (o OptionIntSanitizer) id() string {
+	return optionIntSanitizerID
+}
+
+func (o OptionIntSanitizer) value() interface{} {
+	return o
+}
+
+// OptionUintSanitizer allows users to register a custom sanitizer of unsigned
+// integer values, of any size. See OptionIntSanitizer
+type OptionUintSanitizer struct {
+	Name     string
+	Func     func(v uint64, param string) (uint64, error)
+	After    bool
+	Override bool
+}
+
+var _ Option = OptionUintSanitizer{}
+
+const optionUintSanitizerID = "uint-sanitizer"
+
+func (o OptionUintSanitizer) id() string {
+	return optionUintSanitizerID
+}
+
+func (o OptionUintSanitizer) value() interface{} {
+	return o
+}
+
+// OptionFloatSanitizer allows users to register a custom sanitizer of float32
+// and float64 values. See OptionIntSanitizer
+type OptionFloatSanitizer struct {
+	Name     string
+	Func     func(v float64, param string) (float64, error)
+	After    bool
+	Override bool
+}
+
+var _ Option = OptionFloatSanitizer{}
+
+const optionFloatSanitizerID = "float-sanitizer"
+
+func (o OptionFloatSanitizer) id() string {
+	return optionFloatSanitizerID
+}
+
+func (o OptionFloatSanitizer) value() interface{} {
+	return o
+}
+
+// OptionBoolSanitizer allows users to register a custom sanitizer of bool
+// values. See OptionStringSanitizer
+type OptionBoolSanitizer struct {
+	Name     string
+	Func     func(v bool, param string) (bool, error)
+	After    bool
+	Override bool
+}
+
+var _ Option = OptionBoolSanitizer{}
+
+const optionBoolSanitizerID = "bool-sanitizer"
+
+func (o OptionBoolSanitizer) id() string {
+	return optionBoolSanitizerID
+}
+
+func (o OptionBoolSanitizer) value() interface{} {
+	return o
+}
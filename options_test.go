@@ -46,6 +46,26 @@ func sanitizersEqual(s *Sanitizer, o *Sanitizer) bool {
 		return false
 	}
 
+	if s.collectErrors != o.collectErrors {
+		return false
+	}
+
+	if s.defZero != o.defZero {
+		return false
+	}
+
+	if s.tagOrder != o.tagOrder {
+		return false
+	}
+
+	if !reflect.DeepEqual(s.sanitizersAfter, o.sanitizersAfter) {
+		return false
+	}
+
+	if !reflect.DeepEqual(s.overrides, o.overrides) || !reflect.DeepEqual(s.disabled, o.disabled) {
+		return false
+	}
+
 	if s.sanitizersByName == nil && o.sanitizersByName == nil {
 		return true
 	} else if (s.sanitizersByName != nil && o.sanitizersByName == nil) || (s.sanitizersByName == nil && o.sanitizersByName != nil) {
@@ -156,6 +176,45 @@ func Test_New(t *testing.T) {
 			want:    nil,
 			wantErr: true,
 		},
+		{
+			name: "valid collect errors option",
+			args: args{
+				options: []Option{
+					OptionCollectErrors{Value: true},
+				},
+			},
+			want: &Sanitizer{
+				tagName:       DefaultTagName,
+				collectErrors: true,
+			},
+			wantErr: false,
+		},
+		{
+			name: "valid def zero option",
+			args: args{
+				options: []Option{
+					OptionDefZero{Value: true},
+				},
+			},
+			want: &Sanitizer{
+				tagName: DefaultTagName,
+				defZero: true,
+			},
+			wantErr: false,
+		},
+		{
+			name: "valid tag order option",
+			args: args{
+				options: []Option{
+					OptionTagOrder{Value: true},
+				},
+			},
+			want: &Sanitizer{
+				tagName:  DefaultTagName,
+				tagOrder: true,
+			},
+			wantErr: false,
+		},
 		{
 			name: "valid sanitizer func option",
 			args: args{
@@ -171,6 +230,101 @@ func Test_New(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "valid sanitizer func option run after the built-ins",
+			args: args{
+				options: []Option{
+					OptionSanitizerFunc{Name: "capfirst", Sanitizer: capFirst, After: true},
+				},
+			},
+			want: &Sanitizer{
+				tagName: DefaultTagName,
+				sanitizersByName: map[string]SanitizerFunc{
+					"capfirst": capFirst,
+				},
+				sanitizersAfter: map[string]bool{
+					"capfirst": true,
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "valid sanitizer func option overriding a built-in",
+			args: args{
+				options: []Option{
+					OptionSanitizerFunc{Name: "lower", Sanitizer: capFirst, Override: true},
+				},
+			},
+			want: &Sanitizer{
+				tagName: DefaultTagName,
+				sanitizersByName: map[string]SanitizerFunc{
+					"lower": capFirst,
+				},
+				overrides: map[string]bool{
+					"lower": true,
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "sanitizer func option using the name of a built-in",
+			args: args{
+				options: []Option{
+					OptionSanitizerFunc{Name: "lower", Sanitizer: capFirst},
+				},
+			},
+			want: &Sanitizer{
+				tagName: DefaultTagName,
+				sanitizersByName: map[string]SanitizerFunc{
+					"lower": capFirst,
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "sanitizer func option overriding nothing",
+			args: args{
+				options: []Option{
+					OptionSanitizerFunc{Name: "capfirst", Sanitizer: capFirst, Override: true},
+				},
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "valid disable tags option",
+			args: args{
+				options: []Option{
+					OptionDisableTags{Names: []string{"xss", "title", "xss"}},
+				},
+			},
+			want: &Sanitizer{
+				tagName:  DefaultTagName,
+				disabled: []string{"xss", "title"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "disable tags option with an unknown tag",
+			args: args{
+				options: []Option{
+					OptionDisableTags{Names: []string{"shout"}},
+				},
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "tag both disabled and overridden",
+			args: args{
+				options: []Option{
+					OptionStringSanitizer{Name: "lower", Func: keepString, Override: true},
+					OptionDisableTags{Names: []string{"lower"}},
+				},
+			},
+			want:    nil,
+			wantErr: true,
+		},
 		{
 			name: "duplicate sanitizer func option",
 			args: args{
@@ -197,3 +351,14 @@ func Test_New(t *testing.T) {
 		})
 	}
 }
+
+func Test_New_DuplicateSanitizerName(t *testing.T) {
+	_, err := New(
+		OptionSanitizerFunc{Name: "capfirst", Sanitizer: capFirst},
+		OptionSanitizerFunc{Name: "capfirst", Sanitizer: doNothing},
+	)
+	want := `sanitizer already registered with name "capfirst"`
+	if err == nil || err.Error() != want {
+		t.Errorf("New() - got error %v, wanted %q", err, want)
+	}
+}
@@ -1,62 +1,39 @@
 package sanitize
 
 import (
+	"reflect"
 	"strconv"
 )
 
-func parseInt(str string) (int, error) {
-	v, err := strconv.ParseInt(str, 10, 64)
-	return int(v), err
-}
-
-func parseInt8(str string) (int8, error) {
-	v, err := strconv.ParseInt(str, 10, 8)
-	return int8(v), err
-}
-
-func parseInt16(str string) (int16, error) {
-	v, err := strconv.ParseInt(str, 10, 16)
-	return int16(v), err
-}
-
-func parseInt32(str string) (int32, error) {
-	v, err := strconv.ParseInt(str, 10, 32)
-	return int32(v), err
-}
-
-func parseInt64(str string) (int64, error) {
-	return strconv.ParseInt(str, 10, 64)
-}
-
-func parseUint(str string) (uint, error) {
-	v, err := strconv.ParseUint(str, 10, 64)
-	return uint(v), err
-}
-
-func parseUint8(str string) (uint8, error) {
-	v, err := strconv.ParseUint(str, 10, 8)
-	return uint8(v), err
-}
-
-func parseUint16(str string) (uint16, error) {
-	v, err := strconv.ParseUint(str, 10, 16)
-	return uint16(v), err
-}
-
-func parseUint32(str string) (uint32, error) {
-	v, err := strconv.ParseUint(str, 10, 32)
-	return uint32(v), err
-}
-
-func parseUint64(str string) (uint64, error) {
-	return strconv.ParseUint(str, 10, 64)
-}
-
-func parseFloat32(str string) (float32, error) {
-	v, err := strconv.ParseFloat(str, 32)
-	return float32(v), err
-}
-
-func parseFloat64(str string) (float64, error) {
-	return strconv.ParseFloat(str, 64)
-}
+// parseNumber parses str as a base 10 number of type T, reporting values that
+// do not fit in T as errors.
+func parseNumber[T number](str string) (T, error) {
+	t := reflect.TypeOf(T(0))
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v, err := strconv.ParseInt(str, 10, t.Bits())
+		return T(v), err
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v, err := strconv.ParseUint(str, 10, t.Bits())
+		return T(v), err
+	default:
+		v, err := strconv.ParseFloat(str, t.Bits())
+		return T(v), err
+	}
+}
+
+// The parsers of every numeric type.
+var (
+	parseInt     = parseNumber[int]
+	parseInt8    = parseNumber[int8]
+	parseInt16   = parseNumber[int16]
+	parseInt32   = parseNumber[int32]
+	parseInt64   = parseNumber[int64]
+	parseUint    = parseNumber[uint]
+	parseUint8   = parseNumber[uint8]
+	parseUint16  = parseNumber[uint16]
+	parseUint32  = parseNumber[uint32]
+	parseUint64  = parseNumber[uint64]
+	parseFloat32 = parseNumber[float32]
+	parseFloat64 = parseNumber[float64]
+)
@@ -0,0 +1,535 @@
+package sanitize
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// scalarRule is the compiled form of the tag components that apply to a
+// single string, numeric or bool value. Tags are parsed once when the rule is
+// compiled, so applying it only has to read and write values.
+type scalarRule struct {
+	// apply sanitizes a settable value of the kind the rule was compiled for,
+	// with the components of phase, or all of them with allPhases. It is nil
+	// when there is nothing to do for non-nil values.
+	apply func(v reflect.Value, phase int) error
+	// def sets a freshly allocated value to the default. It is nil when the
+	// tag has no "def" component.
+	def func(v reflect.Value) error
+}
+
+// allPhases applies every component of a plan at once. With OptionTagOrder,
+// custom sanitizers written between built-in components split them into
+// phases: phase 0 holds the components written before the first custom
+// sanitizer, phase 1 the ones up to the second, and so on. The plan of the
+// field is then applied one phase at a time around the custom sanitizers, so
+// that the whole tag is still compiled and checked at once.
+const allPhases = -1
+
+// inPhase reports whether the components of phase p run when phase is
+// applied.
+func inPhase(phase, p int) bool {
+	return phase == allPhases || phase == p
+}
+
+// scalarCompiler parses the tag components of a field whose values are of
+// type t into a scalarRule. order lists the names of the components in the
+// order they are written, for the components that are applied in that order.
+type scalarCompiler = func(s *Sanitizer, t reflect.Type, tags map[string]string, order []string) (*scalarRule, error)
+
+var scalarCompilers = map[reflect.Kind]scalarCompiler{
+	reflect.String:  compileStrRule,
+	reflect.Int:     compileNumberRule[int],
+	reflect.Int8:    compileNumberRule[int8],
+	reflect.Int16:   compileNumberRule[int16],
+	reflect.Int32:   compileNumberRule[int32],
+	reflect.Int64:   compileNumberRule[int64],
+	reflect.Uint:    compileNumberRule[uint],
+	reflect.Uint8:   compileNumberRule[uint8],
+	reflect.Uint16:  compileNumberRule[uint16],
+	reflect.Uint32:  compileNumberRule[uint32],
+	reflect.Uint64:  compileNumberRule[uint64],
+	reflect.Float32: compileNumberRule[float32],
+	reflect.Float64: compileNumberRule[float64],
+	reflect.Bool:    compileBoolRule,
+}
+
+// structPlan is the compiled form of a struct type: the fields that need any
+// work, with their tags already parsed. Plans only depend on the type and on
+// the Sanitizer options, so they are cached on the Sanitizer.
+type structPlan struct {
+	fields []fieldPlan
+}
+
+type fieldPlan struct {
+	index int
+	name  string
+	steps []fieldStep
+	// err is the error found in the tag of the field. It is only kept in
+	// plans compiled with OptionCollectErrors, which report it every time
+	// the field is reached and still sanitize the other fields.
+	err error
+}
+
+// fieldStep is either a custom sanitizer or the plan of the built-in
+// components of a field, applied for phase.
+type fieldStep struct {
+	custom *customSanitizer
+	value  *valuePlan
+	phase  int
+}
+
+type customSanitizer struct {
+	name string
+	fn   SanitizerFunc
+}
+
+// valuePlan describes what has to happen to a value of a given type. The
+// value is either a scalar with a rule, or a pointer, slice, array, map or
+// struct that leads to more values. The kind is always the underlying kind,
+// so defined types such as `type Email string` share the plans of their
+// builtin type.
+type valuePlan struct {
+	kind   reflect.Kind
+	elem   *valuePlan  // Ptr, Slice, Array and Map
+	key    *valuePlan  // Map
+	slice  *sliceRule  // Slice
+	scalar *scalarRule // String, Bool and numeric kinds
+	strct  *structPlan // Struct
+	// defZero also sets the default of scalars holding their zero value
+	defZero bool
+	// phase is the phase in which the plan's own work runs: the slice rule,
+	// the renaming of map keys, the nested struct, or the default of a
+	// scalar.
+	phase int
+}
+
+// rootPlanFor returns the plan for values of type t given to Sanitize, such
+// as *T, []T or map[K]*T where T is a struct. A nil plan means there is
+// nothing to sanitize in values of that type.
+func (s *Sanitizer) rootPlanFor(t reflect.Type) (*valuePlan, error) {
+	if s.roots != nil {
+		if p, ok := s.roots.Load(t); ok {
+			return p.(*valuePlan), nil
+		}
+	}
+
+	c := newPlanCompiler(s)
+	p, err := c.valuePlan(t, nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	s.storePlans(c)
+	if s.roots != nil {
+		s.roots.LoadOrStore(t, p)
+	}
+
+	return p, nil
+}
+
+// storePlans caches the struct plans compiled by c.
+func (s *Sanitizer) storePlans(c *planCompiler) {
+	if s.plans == nil {
+		return
+	}
+	for t, p := range c.compiled {
+		s.plans.LoadOrStore(t, p)
+	}
+}
+
+// planCompiler compiles struct plans. Plans are registered before their
+// fields are compiled so that recursive types refer back to the plan being
+// built instead of looping forever.
+type planCompiler struct {
+	s        *Sanitizer
+	compiled map[reflect.Type]*structPlan
+	// strict also rejects tags that would be ignored or only fail once a
+	// value reaches them. Strict compilers never reuse cached plans, since
+	// those may have been compiled leniently.
+	strict bool
+	// collect carries on after a bad field, so every bad field of a struct
+	// is reported at once in a MultiError.
+	collect bool
+}
+
+func newPlanCompiler(s *Sanitizer) *planCompiler {
+	return &planCompiler{
+		s:        s,
+		compiled: make(map[reflect.Type]*structPlan),
+		collect:  s.collectErrors,
+	}
+}
+
+func (c *planCompiler) structPlan(t reflect.Type) (*structPlan, error) {
+	if c.s.plans != nil && !c.strict {
+		if p, ok := c.s.plans.Load(t); ok {
+			return p.(*structPlan), nil
+		}
+	}
+	if p, ok := c.compiled[t]; ok {
+		return p, nil
+	}
+
+	p := &structPlan{}
+	c.compiled[t] = p
+
+	var errs MultiError
+	fail := func(field reflect.StructField, err error) error {
+		if c.collect && !c.strict {
+			p.fields = append(p.fields, fieldPlan{index: field.Index[0], name: field.Name, err: err})
+			return nil
+		}
+		err = prefixPath(field.Name, err)
+		if !c.collect {
+			return err
+		}
+		if m, ok := err.(MultiError); ok {
+			errs = append(errs, m...)
+		} else {
+			errs = append(errs, err)
+		}
+		return nil
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		// Unexported fields can not be set. Embedded structs are the
+		// exception, since their exported fields are promoted.
+		var comps []tagComponent
+		if field.PkgPath == "" {
+			var err error
+			if comps, err = c.s.fieldTagComponents(field.Tag); err != nil {
+				if err := fail(field, err); err != nil {
+					return nil, err
+				}
+				continue
+			}
+		} else if !field.Anonymous {
+			continue
+		}
+		tags := tagComponents(comps)
+
+		if c.strict {
+			if err := c.checkTags(field.Type, tags); err != nil {
+				if err := fail(field, err); err != nil {
+					return nil, err
+				}
+				continue
+			}
+		}
+
+		steps, err := c.fieldSteps(field.Type, comps, tags)
+		if err != nil {
+			if err := fail(field, err); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if len(steps) == 0 {
+			continue
+		}
+		p.fields = append(p.fields, fieldPlan{
+			index: i,
+			name:  field.Name,
+			steps: steps,
+		})
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	return p, nil
+}
+
+// fieldSteps compiles the steps of a field of type t with the tag components
+// comps, whose values are in tags. Custom sanitizers are kept in the plan so
+// that the tag's value does not have to be looked up again for every struct.
+//
+// By default custom sanitizers run in the order they are written, before the
+// built-in components or after them if they were registered with After. With
+// OptionTagOrder, the plan of the built-in components is applied one phase at
+// a time, with each custom sanitizer in between, so that everything runs in
+// the order it is written.
+func (c *planCompiler) fieldSteps(t reflect.Type, comps []tagComponent, tags map[string]string) ([]fieldStep, error) {
+	value, err := c.valuePlan(t, tags, tagNames(comps), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var steps []fieldStep
+	if !c.s.tagOrder {
+		var after []fieldStep
+		seen := make(map[string]bool)
+		for _, comp := range comps {
+			sanitizerFunc, ok := c.s.sanitizersByName[comp.name]
+			if !ok || seen[comp.name] {
+				continue
+			}
+			seen[comp.name] = true
+			step := fieldStep{custom: &customSanitizer{name: comp.name, fn: sanitizerFunc}}
+			if c.s.sanitizersAfter[comp.name] {
+				after = append(after, step)
+			} else {
+				steps = append(steps, step)
+			}
+		}
+		if value != nil {
+			steps = append(steps, fieldStep{value: value, phase: allPhases})
+		}
+		return append(steps, after...), nil
+	}
+
+	phase := 0
+	for _, comp := range comps {
+		sanitizerFunc, ok := c.s.sanitizersByName[comp.name]
+		if !ok {
+			continue
+		}
+		if value != nil {
+			steps = append(steps, fieldStep{value: value, phase: phase})
+		}
+		steps = append(steps, fieldStep{custom: &customSanitizer{name: comp.name, fn: sanitizerFunc}})
+		phase++
+	}
+	if value != nil {
+		steps = append(steps, fieldStep{value: value, phase: phase})
+	}
+	return steps, nil
+}
+
+// valuePlan compiles the plan for values of type t. Scalars are compiled with
+// compile, or with the compiler registered for their kind when compile is
+// nil, and are given order (see scalarCompiler). A nil plan means there is
+// nothing to do for that type.
+func (c *planCompiler) valuePlan(t reflect.Type, tags map[string]string, order []string, compile scalarCompiler) (*valuePlan, error) {
+	if err := c.s.checkDisabled(tags); err != nil {
+		return nil, err
+	}
+	builtins := c.s.builtinTags(tags)
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		elem, err := c.valuePlan(t.Elem(), tags, order, compile)
+		if err != nil || elem == nil {
+			return nil, err
+		}
+		return &valuePlan{kind: reflect.Ptr, elem: elem}, nil
+	case reflect.Slice:
+		rule, err := compileSliceRule(t, builtins)
+		if err != nil {
+			return nil, err
+		}
+		elem, err := c.valuePlan(t.Elem(), tags, order, compile)
+		if err != nil {
+			return nil, err
+		}
+		if elem == nil && rule.isEmpty() {
+			return nil, nil
+		}
+		phase := c.s.firstPhase(order, builtins, "dropnil", "maxsize")
+		return &valuePlan{kind: reflect.Slice, elem: elem, slice: rule, phase: phase}, nil
+	case reflect.Array:
+		// Arrays can not be resized, so only the elements are sanitized
+		elem, err := c.valuePlan(t.Elem(), tags, order, compile)
+		if err != nil || elem == nil {
+			return nil, err
+		}
+		return &valuePlan{kind: reflect.Array, elem: elem}, nil
+	case reflect.Map:
+		key, err := c.mapKeysPlan(t.Key(), builtins)
+		if err != nil {
+			return nil, err
+		}
+		elem, err := c.valuePlan(t.Elem(), tags, order, compile)
+		if err != nil {
+			return nil, err
+		}
+		if elem == nil && key == nil {
+			return nil, nil
+		}
+		phase := c.s.phaseAt(order, tagIndex(order, "keys"))
+		return &valuePlan{kind: reflect.Map, elem: elem, key: key, phase: phase}, nil
+	case reflect.Struct:
+		p, err := c.structPlan(t)
+		if err != nil {
+			return nil, err
+		}
+		// Nested structs are sanitized once, in the last phase
+		phase := c.s.phaseAt(order, len(order))
+		return &valuePlan{kind: reflect.Struct, strct: p, phase: phase}, nil
+	}
+
+	if len(tags) == 0 {
+		return nil, nil
+	}
+	if compile == nil {
+		var ok bool
+		if compile, ok = scalarCompilers[t.Kind()]; !ok {
+			return nil, nil
+		}
+	}
+	rule, err := compile(c.s, t, tags, order)
+	if err != nil {
+		return nil, err
+	}
+	// Typed custom sanitizers of strings are part of the string transforms
+	if t.Kind() != reflect.String {
+		c.s.withTypedSteps(rule, t, tags, order)
+	}
+	if c.strict && rule.def != nil {
+		// Defaults may only be built once a nil pointer needs them
+		if err := rule.def(reflect.New(t).Elem()); err != nil {
+			return nil, err
+		}
+	}
+	_, defZero := builtins["defzero"]
+	if defZero && rule.def == nil {
+		return nil, tagError("defzero", fmt.Errorf("defzero needs a def tag component"))
+	}
+	if rule.apply == nil && rule.def == nil {
+		return nil, nil
+	}
+
+	return &valuePlan{
+		kind:    t.Kind(),
+		scalar:  rule,
+		defZero: defZero || c.s.defZero,
+		phase:   c.s.phaseAt(order, tagIndex(order, "def")),
+	}, nil
+}
+
+func (p *structPlan) apply(w *walker, v reflect.Value) error {
+	for i := range p.fields {
+		f := &p.fields[i]
+
+		w.push(pathSegment{field: f.name})
+
+		if f.err != nil {
+			if err := w.fail("", f.err); err != nil {
+				return err
+			}
+			w.pop()
+			continue
+		}
+
+		// Tag's value can be re-resolved inside custom sanitizers
+		for _, step := range f.steps {
+			if step.custom != nil {
+				if err := step.custom.fn(*w.s, v, f.index); err != nil {
+					if err := w.fail(step.custom.name, err); err != nil {
+						return err
+					}
+				}
+				continue
+			}
+			if err := step.value.applyPhase(w, v.Field(f.index), step.phase); err != nil {
+				return err
+			}
+		}
+
+		w.pop()
+	}
+
+	return nil
+}
+
+func (p *valuePlan) apply(w *walker, v reflect.Value) error {
+	return p.applyPhase(w, v, allPhases)
+}
+
+// applyPhase sanitizes v with the components of phase only, or with all of
+// them with allPhases.
+func (p *valuePlan) applyPhase(w *walker, v reflect.Value, phase int) error {
+	switch p.kind {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			return p.elem.applyPhase(w, v.Elem(), phase)
+		}
+		// Pointer, nil, and we have a default: set it, once its phase is
+		// reached
+		if p.elem.scalar != nil && p.elem.scalar.def != nil && (phase == allPhases || phase >= p.elem.phase) {
+			defValue := reflect.New(v.Type().Elem())
+			if err := p.elem.scalar.def(defValue.Elem()); err != nil {
+				return w.fail("def", err)
+			}
+			v.Set(defValue)
+		}
+		return nil
+	case reflect.Slice, reflect.Array:
+		// The slice itself is sanitized first, so that elements that are
+		// about to be dropped are not processed
+		if p.slice != nil && inPhase(phase, p.phase) {
+			if err := p.slice.apply(v); err != nil {
+				return w.fail("", err)
+			}
+		}
+		if p.elem == nil {
+			return nil
+		}
+		for i := 0; i < v.Len(); i++ {
+			w.push(pathSegment{index: i})
+			if err := p.elem.applyPhase(w, v.Index(i), phase); err != nil {
+				return err
+			}
+			w.pop()
+		}
+		return nil
+	case reflect.Map:
+		return p.applyMap(w, v, phase)
+	case reflect.Struct:
+		if !inPhase(phase, p.phase) {
+			return nil
+		}
+		return p.strct.apply(w, v)
+	}
+
+	// Zero values get the default like nil pointers do, without the other
+	// components. Values that the other components turn into zero (e.g. a
+	// blank string that is trimmed) get it too.
+	hasDef := p.defZero && p.scalar.def != nil
+	if hasDef && inPhase(phase, p.phase) && v.IsZero() {
+		return p.applyDef(w, v)
+	}
+	if p.scalar.apply != nil {
+		if err := p.scalar.apply(v, phase); err != nil {
+			return w.fail("", err)
+		}
+	}
+	if hasDef && (phase == allPhases || phase >= p.phase) && v.IsZero() {
+		return p.applyDef(w, v)
+	}
+	return nil
+}
+
+// applyDef sets the scalar v to the default of p.
+func (p *valuePlan) applyDef(w *walker, v reflect.Value) error {
+	if err := p.scalar.def(v); err != nil {
+		return w.fail("def", err)
+	}
+	return nil
+}
+
+// sanitizeScalarField compiles the tags of the field idx of structValue with
+// compile and applies them straight away, without going through the cached
+// plans.
+func (s Sanitizer) sanitizeScalarField(structValue reflect.Value, idx int, compile scalarCompiler) error {
+	field := structValue.Type().Field(idx)
+
+	comps, err := s.fieldTagComponents(field.Tag)
+	if err != nil {
+		return err
+	}
+	plan, err := newPlanCompiler(&s).valuePlan(field.Type, tagComponents(comps), tagNames(comps), compile)
+	if err != nil || plan == nil {
+		return err
+	}
+
+	w := newWalker(&s)
+	if err := plan.apply(w, structValue.Field(idx)); err != nil {
+		return err
+	}
+	return w.err()
+}
@@ -0,0 +1,185 @@
+package sanitize
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+)
+
+type testPlanNode struct {
+	Name     string          `san:"trim,lower"`
+	Children []*testPlanNode `san:"maxsize=2"`
+}
+
+type testPlanBenchItem struct {
+	Name  string  `san:"trim,max=10,lower"`
+	Price float64 `san:"min=0.5,max=99.9"`
+	Qty   *int    `san:"min=1,max=20,def=1"`
+}
+
+type testPlanBench struct {
+	ID    string              `san:"trim,upper"`
+	Email *string             `san:"trim,lower,def=unknown"`
+	Age   int                 `san:"min=18,max=99"`
+	Tags  []string            `san:"maxsize=5,trim,lower"`
+	Items []testPlanBenchItem `san:"maxsize=10"`
+}
+
+func newTestPlanBench() *testPlanBench {
+	qty := 50
+	return &testPlanBench{
+		ID:   " ab-123 ",
+		Age:  7,
+		Tags: []string{" A ", " B ", " C "},
+		Items: []testPlanBenchItem{
+			{Name: " A very long product name ", Price: 120, Qty: &qty},
+			{Name: " Short ", Price: 0.1},
+		},
+	}
+}
+
+func Test_rootPlanFor_Cache(t *testing.T) {
+	s, _ := New()
+
+	v := newTestPlanBench()
+	if err := s.Sanitize(v); err != nil {
+		t.Fatalf("Sanitize() - got unexpected error %v", err)
+	}
+
+	for _, typ := range []reflect.Type{
+		reflect.TypeOf(testPlanBench{}),
+		reflect.TypeOf(testPlanBenchItem{}),
+	} {
+		if _, ok := s.plans.Load(typ); !ok {
+			t.Errorf("rootPlanFor() - plan for %s was not cached", typ)
+		}
+	}
+
+	p1, _ := s.rootPlanFor(reflect.TypeOf(v))
+	p2, _ := s.rootPlanFor(reflect.TypeOf(v))
+	if p1 != p2 {
+		t.Errorf("rootPlanFor() - got a new plan for an already compiled type")
+	}
+}
+
+func Test_rootPlanFor_BadTagsAreNotCached(t *testing.T) {
+	type TestBadStruct struct {
+		Field int `san:"max=no"`
+	}
+
+	s, _ := New()
+
+	if err := s.Sanitize(&TestBadStruct{}); err == nil {
+		t.Fatal("Sanitize() - did not receive expected error")
+	}
+	if _, ok := s.plans.Load(reflect.TypeOf(TestBadStruct{})); ok {
+		t.Errorf("rootPlanFor() - plan with bad tags was cached")
+	}
+}
+
+func Test_rootPlanFor_Recursive(t *testing.T) {
+	s, _ := New()
+
+	v := &testPlanNode{
+		Name: " ROOT ",
+		Children: []*testPlanNode{
+			{Name: " A ", Children: []*testPlanNode{{Name: " AA "}}},
+			{Name: " B "},
+			{Name: " C "},
+		},
+	}
+	want := &testPlanNode{
+		Name: "root",
+		Children: []*testPlanNode{
+			{Name: "a", Children: []*testPlanNode{{Name: "aa"}}},
+			{Name: "b"},
+		},
+	}
+
+	if err := s.Sanitize(v); err != nil {
+		t.Fatalf("Sanitize() - got unexpected error %v", err)
+	}
+	if !reflect.DeepEqual(v, want) {
+		t.Errorf("Sanitize() - got %+v but wanted %+v", v, want)
+	}
+}
+
+func Test_rootPlanFor_UnexportedFields(t *testing.T) {
+	type inner struct {
+		Name string `san:"trim"`
+	}
+	type TestStruct struct {
+		inner
+		name string `san:"trim"`
+	}
+
+	s, _ := New()
+
+	v := &TestStruct{
+		inner: inner{Name: " exported "},
+		name:  " unexported ",
+	}
+	want := &TestStruct{
+		inner: inner{Name: "exported"},
+		name:  " unexported ",
+	}
+
+	if err := s.Sanitize(v); err != nil {
+		t.Fatalf("Sanitize() - got unexpected error %v", err)
+	}
+	if !reflect.DeepEqual(v, want) {
+		t.Errorf("Sanitize() - got %+v but wanted %+v", v, want)
+	}
+}
+
+func Test_rootPlanFor_Concurrent(t *testing.T) {
+	s, _ := New()
+
+	want := newTestPlanBench()
+	if err := s.Sanitize(want); err != nil {
+		t.Fatalf("Sanitize() - got unexpected error %v", err)
+	}
+
+	// Use a fresh sanitizer so the goroutines race to compile the plans
+	s, _ = New()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v := newTestPlanBench()
+			if err := s.Sanitize(v); err != nil {
+				t.Errorf("Sanitize() - got unexpected error %v", err)
+			}
+			if !reflect.DeepEqual(v, want) {
+				t.Errorf("Sanitize() - got %+v but wanted %+v", v, want)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// BenchmarkSanitize_Uncached uses a Sanitizer without a plan cache, so tags
+// are parsed on every call like they were before plans were introduced.
+func BenchmarkSanitize_Uncached(b *testing.B) {
+	s := &Sanitizer{tagName: DefaultTagName}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := s.Sanitize(newTestPlanBench()); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSanitize_Planned(b *testing.B) {
+	s, _ := New()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := s.Sanitize(newTestPlanBench()); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package sanitize
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+)
+
+// roundingMode is the direction in which a floatRounder rounds values that
+// fall between two multiples of its unit.
+type roundingMode int
+
+const (
+	roundHalfUp roundingMode = iota
+	roundHalfEven
+	roundFloor
+	roundCeil
+	roundTrunc
+)
+
+// floatRounder rounds floats to a multiple of unit, such as 0.01 for
+// "round=2" or 0.05 for "step=0.05". Values are rounded as the shortest
+// decimal that represents them, so 1.005 rounds to 1.01 with "round=2" even
+// though its binary value is slightly below 1.005.
+type floatRounder struct {
+	unit *big.Rat
+	mode roundingMode
+	bits int
+}
+
+// compileFloatRounding parses the rounding tag components of a float field
+// of the given bit size. It returns nil if there are none.
+func compileFloatRounding(tags map[string]string, bits int) (*floatRounder, error) {
+	if err := checkConflicts(tags, floatPrecisionTags); err != nil {
+		return nil, err
+	}
+
+	r := &floatRounder{unit: big.NewRat(1, 1), bits: bits}
+	found := false
+
+	if v, ok := tags["round"]; ok {
+		// A bare "round" rounds to whole numbers
+		digits := uint64(0)
+		if v != "" {
+			var err error
+			digits, err = strconv.ParseUint(v, 10, 8)
+			if err != nil {
+				return nil, tagError("round", err)
+			}
+		}
+		r.unit.SetFrac(big.NewInt(1), new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(digits)), nil))
+		found = true
+	}
+	if v, ok := tags["step"]; ok {
+		if _, ok := r.unit.SetString(v); !ok || r.unit.Sign() <= 0 {
+			return nil, tagError("step", fmt.Errorf("step must be a number above 0, got %q", v))
+		}
+		found = true
+	}
+
+	mode, hasMode, err := compileRoundingMode(tags)
+	if err != nil {
+		return nil, err
+	}
+	r.mode = mode
+	found = found || hasMode
+
+	if !found {
+		return nil, nil
+	}
+	return r, nil
+}
+
+// compileRoundingMode parses the tag components that choose the direction of
+// rounding, shared by float rounding and integer multiples. found reports
+// whether there was any.
+func compileRoundingMode(tags map[string]string) (mode roundingMode, found bool, err error) {
+	if err := checkConflicts(tags, roundingDirectionTags); err != nil {
+		return 0, false, err
+	}
+
+	if v, ok := tags["rounding"]; ok {
+		switch v {
+		case "halfup":
+			return roundHalfUp, true, nil
+		case "halfeven":
+			return roundHalfEven, true, nil
+		default:
+			return 0, false, tagError("rounding", fmt.Errorf("unknown rounding mode %q, expected halfup or halfeven", v))
+		}
+	}
+	for name, mode := range map[string]roundingMode{"floor": roundFloor, "ceil": roundCeil, "trunc": roundTrunc} {
+		if _, ok := tags[name]; ok {
+			return mode, true, nil
+		}
+	}
+	return roundHalfUp, false, nil
+}
+
+// round returns the multiple of r.unit closest to x in the direction of
+// r.mode. NaN and infinities are returned as they are.
+func (r *floatRounder) round(x float64) float64 {
+	return r.roundMode(x, r.mode)
+}
+
+// roundMode is like round, but rounds in the direction of mode.
+func (r *floatRounder) roundMode(x float64, mode roundingMode) float64 {
+	if math.IsNaN(x) || math.IsInf(x, 0) {
+		return x
+	}
+
+	v, _ := new(big.Rat).SetString(strconv.FormatFloat(x, 'g', -1, r.bits))
+	q := v.Quo(v, r.unit)
+
+	// n is q truncated towards zero, rem the fraction left over, which has
+	// the same sign as q
+	n := new(big.Int).Quo(q.Num(), q.Denom())
+	rem := q.Sub(q, new(big.Rat).SetInt(n))
+	sign := int64(rem.Sign())
+
+	switch mode {
+	case roundFloor:
+		if sign < 0 {
+			n.Sub(n, big.NewInt(1))
+		}
+	case roundCeil:
+		if sign > 0 {
+			n.Add(n, big.NewInt(1))
+		}
+	case roundHalfUp, roundHalfEven:
+		c := rem.Abs(rem).Cmp(big.NewRat(1, 2))
+		if c > 0 || c == 0 && (mode == roundHalfUp || n.Bit(0) == 1) {
+			n.Add(n, big.NewInt(sign))
+		}
+	}
+
+	res := new(big.Rat).Mul(new(big.Rat).SetInt(n), r.unit)
+	if r.bits == 32 {
+		f, _ := res.Float32()
+		return float64(f)
+	}
+	f, _ := res.Float64()
+	return f
+}
@@ -0,0 +1,121 @@
+package sanitize
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func Test_floatRounder_round(t *testing.T) {
+	tests := []struct {
+		name string
+		tags map[string]string
+		bits int
+		in   float64
+		want float64
+	}{
+		{name: "round=2 rounds half away from zero.", tags: map[string]string{"round": "2"}, in: 1.005, want: 1.01},
+		{name: "round=2 rounds negative halves away from zero.", tags: map[string]string{"round": "2"}, in: -1.005, want: -1.01},
+		{name: "round=2 rounds down below the half.", tags: map[string]string{"round": "2"}, in: 2.6749, want: 2.67},
+		{name: "Bare round rounds to whole numbers.", tags: map[string]string{"round": ""}, in: 2.5, want: 3},
+		{name: "round=0 rounds to whole numbers.", tags: map[string]string{"round": "0"}, in: 2.4, want: 2},
+		{name: "halfeven rounds halves to the even neighbour.", tags: map[string]string{"round": "1", "rounding": "halfeven"}, in: 0.25, want: 0.2},
+		{name: "halfeven rounds odd halves up.", tags: map[string]string{"round": "1", "rounding": "halfeven"}, in: 0.35, want: 0.4},
+		{name: "halfeven rounds above the half up.", tags: map[string]string{"round": "1", "rounding": "halfeven"}, in: 0.251, want: 0.3},
+		{name: "halfup is the default.", tags: map[string]string{"round": "1", "rounding": "halfup"}, in: 0.25, want: 0.3},
+		{name: "floor rounds down.", tags: map[string]string{"floor": ""}, in: 2.9, want: 2},
+		{name: "floor rounds negatives down.", tags: map[string]string{"floor": ""}, in: -2.1, want: -3},
+		{name: "ceil rounds up.", tags: map[string]string{"ceil": ""}, in: 2.1, want: 3},
+		{name: "ceil rounds negatives up.", tags: map[string]string{"ceil": ""}, in: -2.9, want: -2},
+		{name: "trunc rounds towards zero.", tags: map[string]string{"trunc": ""}, in: -2.9, want: -2},
+		{name: "floor works with round.", tags: map[string]string{"round": "2", "floor": ""}, in: 9.999, want: 9.99},
+		{name: "step rounds to the nearest step.", tags: map[string]string{"step": "0.05"}, in: 1.024, want: 1.0},
+		{name: "step rounds halves away from zero.", tags: map[string]string{"step": "0.05"}, in: 1.025, want: 1.05},
+		{name: "step works with ceil.", tags: map[string]string{"step": "0.25", "ceil": ""}, in: 1.01, want: 1.25},
+		{name: "Whole numbers are kept.", tags: map[string]string{"round": "2"}, in: 42, want: 42},
+		{name: "float32 values are rounded as float32.", tags: map[string]string{"round": "2"}, bits: 32, in: float64(float32(1.005)), want: float64(float32(1.01))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bits := tt.bits
+			if bits == 0 {
+				bits = 64
+			}
+			r, err := compileFloatRounding(tt.tags, bits)
+			if err != nil || r == nil {
+				t.Fatalf("compileFloatRounding() - got %v, %v", r, err)
+			}
+			if got := r.round(tt.in); got != tt.want {
+				t.Errorf("round(%v) - got %v but wanted %v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_floatRounder_NaNAndInf(t *testing.T) {
+	r, _ := compileFloatRounding(map[string]string{"round": "2"}, 64)
+	if got := r.round(math.NaN()); !math.IsNaN(got) {
+		t.Errorf("round(NaN) - got %v", got)
+	}
+	if got := r.round(math.Inf(-1)); !math.IsInf(got, -1) {
+		t.Errorf("round(-Inf) - got %v", got)
+	}
+}
+
+func Test_compileFloatRounding_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		tags    map[string]string
+		wantErr string
+	}{
+		{name: "Rejects round and step together.", tags: map[string]string{"round": "2", "step": "0.5"}, wantErr: "conflicting tag components round and step"},
+		{name: "Rejects two directions.", tags: map[string]string{"floor": "", "ceil": ""}, wantErr: "conflicting tag components floor and ceil"},
+		{name: "Rejects a direction with a rounding mode.", tags: map[string]string{"trunc": "", "rounding": "halfeven"}, wantErr: "conflicting tag components trunc and rounding"},
+		{name: "Rejects a negative round.", tags: map[string]string{"round": "-1"}, wantErr: "(round)"},
+		{name: "Rejects a non-numeric round.", tags: map[string]string{"round": "two"}, wantErr: "(round)"},
+		{name: "Rejects a zero step.", tags: map[string]string{"step": "0"}, wantErr: "step must be a number above 0"},
+		{name: "Rejects a non-numeric step.", tags: map[string]string{"step": "abc"}, wantErr: "step must be a number above 0"},
+		{name: "Rejects an unknown rounding mode.", tags: map[string]string{"round": "1", "rounding": "bankers"}, wantErr: `unknown rounding mode "bankers"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := compileFloatRounding(tt.tags, 64)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("compileFloatRounding() - got error %v, wanted it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_Sanitize_FloatRounding(t *testing.T) {
+	type TestRounding struct {
+		Price    float64  `san:"min=0,max=100,round=2"`
+		Discount *float32 `san:"step=0.05,def=0"`
+		Reading  float64  `san:"max=10.5,floor"`
+		Count    int      `san:"min=1"`
+		Capped   float64  `san:"max=2.5,round"`
+		Raised   float32  `san:"min=-2.5,round"`
+	}
+
+	s, _ := New()
+
+	discount := float32(0.123)
+	v := &TestRounding{Price: 100.456, Discount: &discount, Reading: 12, Count: 0, Capped: 100, Raised: -100}
+	if err := s.Sanitize(v); err != nil {
+		t.Fatalf("Sanitize() - got unexpected error %v", err)
+	}
+	if v.Price != 100 || *v.Discount != 0.1 || v.Reading != 10 || v.Count != 1 {
+		t.Errorf("Sanitize() - got %+v (discount %v)", v, *v.Discount)
+	}
+	// Rounding does not leave the range of min and max
+	if v.Capped != 2 || v.Raised != -2 {
+		t.Errorf("Sanitize() - got %v and %v, wanted 2 and -2", v.Capped, v.Raised)
+	}
+
+	type TestIntRounding struct {
+		Count int `san:"round=2"`
+	}
+	if err := s.Validate(TestIntRounding{}); err == nil || !strings.Contains(err.Error(), `unknown tag component "round"`) {
+		t.Errorf("Validate() - got error %v, wanted round to be rejected on int fields", err)
+	}
+}
@@ -5,6 +5,7 @@ package sanitize
 import (
 	"fmt"
 	"reflect"
+	"sync"
 )
 
 // DefaultTagName instance is the name of the tag that must be present on the string
@@ -13,7 +14,8 @@ const DefaultTagName = "san"
 
 type SanitizerFunc func(s Sanitizer, structValue reflect.Value, idx int) error
 
-// Sanitizer instance
+// Sanitizer instance. The tags of each struct type are compiled once and
+// cached, so a Sanitizer should be reused. It is safe for concurrent use.
 type Sanitizer struct {
 	tagName        string
 	dateInput      []string
@@ -21,12 +23,33 @@ type Sanitizer struct {
 	dateOutput     string
 
 	sanitizersByName map[string]SanitizerFunc
+	sanitizersAfter  map[string]bool
+	typedSanitizers  map[string]*typedSanitizer
+	htmlPolicies     map[string]*htmlPolicy
+
+	// overrides holds the built-in tag components replaced by a custom
+	// sanitizer, and disabled the ones turned off.
+	overrides map[string]bool
+	disabled  []string
+
+	collectErrors bool
+	defZero       bool
+	tagOrder      bool
+
+	// plans caches the compiled *structPlan of every struct type seen so far,
+	// keyed by reflect.Type. roots caches the *valuePlan of every type given
+	// to Sanitize, keyed by reflect.Type, and of every type and tag given to
+	// SanitizeValue, keyed by valuePlanKey.
+	plans *sync.Map
+	roots *sync.Map
 }
 
 // New sanitizer instance
 func New(options ...Option) (*Sanitizer, error) {
 	s := &Sanitizer{
 		tagName: DefaultTagName,
+		plans:   &sync.Map{},
+		roots:   &sync.Map{},
 	}
 	for _, o := range options {
 		switch o.id() {
@@ -41,202 +64,139 @@ func New(options ...Option) (*Sanitizer, error) {
 			s.dateInput = v.Input
 			s.dateKeepFormat = v.KeepFormat
 			s.dateOutput = v.Output
+		case optionCollectErrorsID:
+			s.collectErrors = o.value().(bool)
+		case optionDefZeroID:
+			s.defZero = o.value().(bool)
+		case optionTagOrderID:
+			s.tagOrder = o.value().(bool)
+		case optionHTMLPolicyID:
+			v := o.value().(OptionHTMLPolicy)
+			if v.Name == "" || v.Name == htmlEscape || v.Name == htmlStrip {
+				return nil, fmt.Errorf("HTML policy name %q is not valid", v.Name)
+			}
+			if _, ok := s.htmlPolicies[v.Name]; ok {
+				return nil, fmt.Errorf("HTML policy already registered with name %q", v.Name)
+			}
+			if s.htmlPolicies == nil {
+				s.htmlPolicies = make(map[string]*htmlPolicy)
+			}
+			s.htmlPolicies[v.Name] = compileHTMLPolicy(v.Policy)
+		case optionDisableTagsID:
+			for _, name := range o.value().([]string) {
+				if !isBuiltinTag(name) {
+					return nil, fmt.Errorf("can not disable %q, it is not a built-in tag component", name)
+				}
+				if !hasTag(s.disabled, name) {
+					s.disabled = append(s.disabled, name)
+				}
+			}
+		case optionStringSanitizerID, optionIntSanitizerID, optionUintSanitizerID,
+			optionFloatSanitizerID, optionBoolSanitizerID:
+			if err := s.registerSanitizer(o); err != nil {
+				return nil, err
+			}
 		case optionSanitizerFuncID:
 			if s.sanitizersByName == nil {
 				s.sanitizersByName = make(map[string]SanitizerFunc)
 			}
 
 			v := o.(OptionSanitizerFunc)
+			if _, ok := s.typedSanitizers[v.Name]; ok {
+				return nil, fmt.Errorf("sanitizer already registered with name %q", v.Name)
+			}
 			if _, ok := s.sanitizersByName[v.Name]; ok {
-				return nil, fmt.Errorf("sanitizer already registered with name %q", o.id())
+				return nil, fmt.Errorf("sanitizer already registered with name %q", v.Name)
+			}
+			if err := s.registerOverride(v.Name, v.Override); err != nil {
+				return nil, err
 			}
 			s.sanitizersByName[v.Name] = o.value().(SanitizerFunc)
+			if v.After {
+				if s.sanitizersAfter == nil {
+					s.sanitizersAfter = make(map[string]bool)
+				}
+				s.sanitizersAfter[v.Name] = true
+			}
 		default:
 			return nil, fmt.Errorf("option %q is not valid", o.id())
 		}
 	}
+	for _, name := range s.disabled {
+		if s.overrides[name] {
+			return nil, fmt.Errorf("tag component %q can not be both disabled and overridden", name)
+		}
+	}
 	return s, nil
 }
 
+// registerOverride checks that override is only set for the name of a
+// built-in tag component, and records the override. Custom sanitizers that
+// use the name of a built-in component without override run along with it.
+func (s *Sanitizer) registerOverride(name string, override bool) error {
+	if !isBuiltinTag(name) && override {
+		return fmt.Errorf("sanitizer %q can not override a built-in tag component, there is none with that name", name)
+	}
+	if override {
+		if s.overrides == nil {
+			s.overrides = make(map[string]bool)
+		}
+		s.overrides[name] = true
+	}
+	return nil
+}
+
 // Sanitize performs sanitization on all fields of any struct, so long
 // as the sanitization tag ("san" by default) has been defined on the string
 // fields of the struct. The argument s must be the address of a struct to
-// mutate.
+// mutate, or a slice, array or map of structs (or of pointers to structs).
+// Slices and maps can be passed directly since their elements can be changed
+// in place, arrays need to be passed by address. Anything that can not be
+// changed in place, such as a struct passed by value or nil, is reported as
+// an error.
 //
 // Will recursively check all struct, *struct, string, *string, int64, *int64,
-// float64, *float64, bool, and *bool fields. Pointers are dereferenced and the
-// data pointed to will be sanitized.
+// float64, *float64, bool, and *bool fields, as well as fields of defined types
+// based on them (e.g. `type Email string`), and slices, arrays and maps of
+// them. Pointers are dereferenced and the data pointed to will be sanitized.
+// Cyclic values, such as a struct that points back to itself, are not
+// supported: they are walked until the stack runs out.
 //
 // Errors are returned as the struct's fields are processed, so the struct may
 // not be in the same state as when the function began if an error is
-// returned.
+// returned. Errors tied to a field are *FieldError values; with
+// OptionCollectErrors every field is processed and all the errors are
+// returned in a MultiError.
 func (s *Sanitizer) Sanitize(o interface{}) error {
-	// Get both the value and the type of what the pointer points to. Value is
-	// used to mutate underlying data and Type is used to get the name of the
-	// field.
-	return s.sanitizeRec(reflect.ValueOf(o).Elem())
-}
+	v := reflect.ValueOf(o)
+	if err := checkMutable(v); err != nil {
+		return err
+	}
 
-type fieldSanFn = func(s Sanitizer, structValue reflect.Value, idx int) error
+	plan, err := s.rootPlanFor(v.Type())
+	if err != nil || plan == nil {
+		return err
+	}
 
-var fieldSanFns = map[string]fieldSanFn{
-	"string":      sanitizeStrField,
-	"[]string":    sanitizeStrField,
-	"*[]string":   sanitizeStrField,
-	"*string":     sanitizeStrField,
-	"[]*string":   sanitizeStrField,
-	"*[]*string":  sanitizeStrField,
-	"int":         sanitizeIntField,
-	"[]int":       sanitizeIntField,
-	"*[]int":      sanitizeIntField,
-	"*int":        sanitizeIntField,
-	"[]*int":      sanitizeIntField,
-	"*[]*int":     sanitizeIntField,
-	"int8":        sanitizeInt8Field,
-	"[]int8":      sanitizeInt8Field,
-	"*[]int8":     sanitizeInt8Field,
-	"*int8":       sanitizeInt8Field,
-	"[]*int8":     sanitizeInt8Field,
-	"*[]*int8":    sanitizeInt8Field,
-	"int16":       sanitizeInt16Field,
-	"[]int16":     sanitizeInt16Field,
-	"*[]int16":    sanitizeInt16Field,
-	"*int16":      sanitizeInt16Field,
-	"[]*int16":    sanitizeInt16Field,
-	"*[]*int16":   sanitizeInt16Field,
-	"int32":       sanitizeInt32Field,
-	"[]int32":     sanitizeInt32Field,
-	"*[]int32":    sanitizeInt32Field,
-	"*int32":      sanitizeInt32Field,
-	"[]*int32":    sanitizeInt32Field,
-	"*[]*int32":   sanitizeInt32Field,
-	"int64":       sanitizeInt64Field,
-	"[]int64":     sanitizeInt64Field,
-	"*[]int64":    sanitizeInt64Field,
-	"*int64":      sanitizeInt64Field,
-	"[]*int64":    sanitizeInt64Field,
-	"*[]*int64":   sanitizeInt64Field,
-	"uint":        sanitizeUintField,
-	"[]uint":      sanitizeUintField,
-	"*[]uint":     sanitizeUintField,
-	"*uint":       sanitizeUintField,
-	"[]*uint":     sanitizeUintField,
-	"*[]*uint":    sanitizeUintField,
-	"uint8":       sanitizeUint8Field,
-	"[]uint8":     sanitizeUint8Field,
-	"*[]uint8":    sanitizeUint8Field,
-	"*uint8":      sanitizeUint8Field,
-	"[]*uint8":    sanitizeUint8Field,
-	"*[]*uint8":   sanitizeUint8Field,
-	"uint16":      sanitizeUint16Field,
-	"[]uint16":    sanitizeUint16Field,
-	"*[]uint16":   sanitizeUint16Field,
-	"*uint16":     sanitizeUint16Field,
-	"[]*uint16":   sanitizeUint16Field,
-	"*[]*uint16":  sanitizeUint16Field,
-	"uint32":      sanitizeUint32Field,
-	"[]uint32":    sanitizeUint32Field,
-	"*[]uint32":   sanitizeUint32Field,
-	"*uint32":     sanitizeUint32Field,
-	"[]*uint32":   sanitizeUint32Field,
-	"*[]*uint32":  sanitizeUint32Field,
-	"uint64":      sanitizeUint64Field,
-	"[]uint64":    sanitizeUint64Field,
-	"*[]uint64":   sanitizeUint64Field,
-	"*uint64":     sanitizeUint64Field,
-	"[]*uint64":   sanitizeUint64Field,
-	"*[]*uint64":  sanitizeUint64Field,
-	"float32":     sanitizeFloat32Field,
-	"[]float32":   sanitizeFloat32Field,
-	"*[]float32":  sanitizeFloat32Field,
-	"*float32":    sanitizeFloat32Field,
-	"[]*float32":  sanitizeFloat32Field,
-	"*[]*float32": sanitizeFloat32Field,
-	"float64":     sanitizeFloat64Field,
-	"[]float64":   sanitizeFloat64Field,
-	"*[]float64":  sanitizeFloat64Field,
-	"*float64":    sanitizeFloat64Field,
-	"[]*float64":  sanitizeFloat64Field,
-	"*[]*float64": sanitizeFloat64Field,
-	"bool":        sanitizeBoolField,
-	"[]bool":      sanitizeBoolField,
-	"*[]bool":     sanitizeBoolField,
-	"*bool":       sanitizeBoolField,
-	"[]*bool":     sanitizeBoolField,
-	"*[]*bool":    sanitizeBoolField,
+	w := newWalker(s)
+	if err := plan.apply(w, v); err != nil {
+		return err
+	}
+	return w.err()
 }
 
-// Called during recursion, since during recursion we need reflect.Value
-// not interface{}.
-func (s Sanitizer) sanitizeRec(v reflect.Value) error {
-	// Loop through fields of struct. If a struct is encountered, recurse. If a
-	// string is encountered, transform it. Else, skip.
-	for i := 0; i < v.Type().NumField(); i++ {
-		field := v.Field(i)
-		fkind := field.Kind()
-
-		// Prioritize custom sanitizers; tag's value can be re-resolved inside the sanitizer
-		tags := s.fieldTags(v.Type().Field(i).Tag)
-		for tag := range tags {
-			sanitizerFunc, ok := s.sanitizersByName[tag]
-			if !ok {
-				continue
-			}
-
-			if err := sanitizerFunc(s, v, i); err != nil {
-				return err
-			}
-		}
-
-		// If the field is a slice, sanitize it first
-		isPtrToSlice := fkind == reflect.Ptr && field.Elem().Kind() == reflect.Slice
-		isSlice := fkind == reflect.Slice
-		if isSlice || isPtrToSlice {
-			if err := sanitizeSliceField(s, v, i); err != nil {
-				return err
-			}
-		}
-
-		// Do we have a special sanitization function for this type? If so, use it
-		ftype := field.Type().String()
-		if sanFn, ok := fieldSanFns[ftype]; ok {
-			if err := sanFn(s, v, i); err != nil {
-				return err
-			}
-		}
-
-		// If the field is a struct, sanitize it recursively
-		isPtrToStruct := fkind == reflect.Ptr && field.Elem().Kind() == reflect.Struct
-		if fkind == reflect.Struct || isPtrToStruct {
-			if isPtrToStruct {
-				field = field.Elem()
-			}
-			if err := s.sanitizeRec(field); err != nil {
-				return err
-			}
-			continue
-		}
-
-		// If the field is a slice of structs, recurse through them
-		if isSlice || isPtrToSlice {
-			if isPtrToSlice {
-				field = field.Elem()
-			}
-			for i := 0; i < field.Len(); i++ {
-				f := field.Index(i)
-				if f.Kind() == reflect.Ptr {
-					f = f.Elem()
-				}
-				if f.Kind() != reflect.Struct {
-					continue
-				}
-				if err := s.sanitizeRec(f); err != nil {
-					return err
-				}
-			}
-			continue
+// checkMutable makes sure that the values held by v can be changed in place.
+func checkMutable(v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.Invalid:
+		return fmt.Errorf("can not sanitize nil")
+	case reflect.Ptr:
+		if v.IsNil() {
+			return fmt.Errorf("can not sanitize a nil %s", v.Type())
 		}
+	case reflect.Slice, reflect.Map:
+	default:
+		return fmt.Errorf("can not sanitize a %s passed by value, pass a pointer to it instead", v.Type())
 	}
-
 	return nil
 }
@@ -899,3 +899,549 @@ func Test_SliceSanitize(t *testing.T) {
 		})
 	}
 }
+
+func Test_Sanitize_NamedTypes(t *testing.T) {
+	type Email string
+	type Cents int64
+	type Ratio float32
+	type Count uint16
+	type Flag bool
+	type Emails []Email
+	type Amounts []Cents
+
+	type TestStruct struct {
+		Email         Email     `san:"trim,lower,max=12"`
+		EmailPtr      *Email    `san:"trim,lower"`
+		EmailPtrDef   *Email    `san:"def=nobody@x.io"`
+		Price         Cents     `san:"min=100,max=500"`
+		PricePtr      *Cents    `san:"min=100,max=500"`
+		PricePtrDef   *Cents    `san:"min=100,max=500,def=250"`
+		Ratio         Ratio     `san:"max=1.5"`
+		Count         Count     `san:"min=1"`
+		FlagPtrDef    *Flag     `san:"def=true"`
+		Emails        Emails    `san:"trim,lower"`
+		EmailsPtr     *Emails   `san:"trim,lower"`
+		EmailSlice    []Email   `san:"trim,upper,maxsize=1"`
+		EmailPtrSlice []*Email  `san:"trim,lower,def=none"`
+		Amounts       Amounts   `san:"max=10"`
+		AmountPtrs    []*Cents  `san:"min=5"`
+		AmountsPtr    *[]Cents  `san:"max=10"`
+		AmountPtrsPtr *[]*Cents `san:"max=10,def=7"`
+	}
+
+	s, _ := New()
+
+	argEmail := Email(" Borky@Dogs.io ")
+	resEmail := Email("borky@dogs.io")
+	resEmailDef := Email("nobody@x.io")
+	argPrice := Cents(1000)
+	resPrice := Cents(500)
+	resPriceDef := Cents(250)
+	resFlag := Flag(true)
+	argSliEmail := Email(" A@B.C ")
+	resSliEmail := Email("a@b.c")
+	resSliEmailDef := Email("none")
+	argAmount := Cents(1)
+	resAmount := Cents(5)
+	argAmountPtr := Cents(20)
+	resAmountPtr := Cents(10)
+	resAmountPtrDef := Cents(7)
+
+	arg := &TestStruct{
+		Email:         " Borky@Dogs.io.Extra ",
+		EmailPtr:      &argEmail,
+		Price:         50,
+		PricePtr:      &argPrice,
+		Ratio:         3.5,
+		Emails:        Emails{" X@Y.Z "},
+		EmailsPtr:     &Emails{" Y@Z.X "},
+		EmailSlice:    []Email{" a@b.c ", " d@e.f "},
+		EmailPtrSlice: []*Email{&argSliEmail, nil},
+		Amounts:       Amounts{5, 50},
+		AmountPtrs:    []*Cents{&argAmount},
+		AmountsPtr:    &[]Cents{50},
+		AmountPtrsPtr: &[]*Cents{&argAmountPtr, nil},
+	}
+	want := &TestStruct{
+		Email:         "borky@dogs.i",
+		EmailPtr:      &resEmail,
+		EmailPtrDef:   &resEmailDef,
+		Price:         100,
+		PricePtr:      &resPrice,
+		PricePtrDef:   &resPriceDef,
+		Ratio:         1.5,
+		Count:         1,
+		FlagPtrDef:    &resFlag,
+		Emails:        Emails{"x@y.z"},
+		EmailsPtr:     &Emails{"y@z.x"},
+		EmailSlice:    []Email{"A@B.C"},
+		EmailPtrSlice: []*Email{&resSliEmail, &resSliEmailDef},
+		Amounts:       Amounts{5, 10},
+		AmountPtrs:    []*Cents{&resAmount},
+		AmountsPtr:    &[]Cents{10},
+		AmountPtrsPtr: &[]*Cents{&resAmountPtr, &resAmountPtrDef},
+	}
+
+	if err := s.Sanitize(arg); err != nil {
+		t.Fatalf("Sanitize() - got unexpected error %v", err)
+	}
+	if !reflect.DeepEqual(arg, want) {
+		t.Errorf("Sanitize() - got %+v but wanted %+v", arg, want)
+	}
+}
+
+func Test_Sanitize_Array(t *testing.T) {
+	type Point struct {
+		X float64 `san:"min=0,max=100"`
+		Y float64 `san:"min=0,max=100"`
+	}
+	type Code string
+	type TestStruct struct {
+		Codes     [3]string         `san:"trim,upper,max=2"`
+		Named     [2]Code           `san:"trim,lower"`
+		Ints      [3]int            `san:"min=1,max=9"`
+		PtrStrs   [2]*string        `san:"trim,def=none"`
+		Points    [2]Point          `san:""`
+		PtrPoints [2]*Point         `san:""`
+		PtrArray  *[2]uint8         `san:"max=5"`
+		Grid      [2][2]int         `san:"max=1"`
+		ByKey     map[string][2]int `san:"min=3"`
+	}
+
+	s, _ := New()
+
+	argStr := " hello "
+	resStr := "hello"
+	resStrDef := "none"
+
+	v := &TestStruct{
+		Codes:     [3]string{" fra ", "de", " usa"},
+		Named:     [2]Code{" A ", "B"},
+		Ints:      [3]int{0, 5, 10},
+		PtrStrs:   [2]*string{&argStr, nil},
+		Points:    [2]Point{{X: -1, Y: 50}, {X: 200, Y: -3}},
+		PtrPoints: [2]*Point{{X: 101, Y: 1}, nil},
+		PtrArray:  &[2]uint8{3, 30},
+		Grid:      [2][2]int{{0, 2}, {3, 1}},
+		ByKey:     map[string][2]int{"a": {1, 5}},
+	}
+	want := &TestStruct{
+		Codes:     [3]string{"FR", "DE", "US"},
+		Named:     [2]Code{"a", "b"},
+		Ints:      [3]int{1, 5, 9},
+		PtrStrs:   [2]*string{&resStr, &resStrDef},
+		Points:    [2]Point{{X: 0, Y: 50}, {X: 100, Y: 0}},
+		PtrPoints: [2]*Point{{X: 100, Y: 1}, nil},
+		PtrArray:  &[2]uint8{3, 5},
+		Grid:      [2][2]int{{0, 1}, {1, 1}},
+		ByKey:     map[string][2]int{"a": {3, 5}},
+	}
+
+	if err := s.Sanitize(v); err != nil {
+		t.Fatalf("Sanitize() - got unexpected error %v", err)
+	}
+	if !reflect.DeepEqual(v, want) {
+		t.Errorf("Sanitize() - got %+v but wanted %+v", v, want)
+	}
+
+	type TestMaxsize struct {
+		Codes [3]string `san:"maxsize=2"`
+	}
+	if err := s.Validate(TestMaxsize{}); err == nil {
+		t.Error("Validate() - did not receive expected error for maxsize on an array")
+	}
+}
+
+func Test_Sanitize_TopLevel(t *testing.T) {
+	type User struct {
+		Name string `san:"trim,lower"`
+	}
+
+	s, _ := New()
+
+	str := " Hello "
+	user := &User{Name: " A "}
+	userPtr := &User{Name: " B "}
+	resUserPtr := &User{Name: "b"}
+	var nilUser *User
+
+	tests := []struct {
+		name    string
+		v       interface{}
+		want    interface{}
+		wantErr string
+	}{
+		{
+			name: "Sanitizes a pointer to a slice of structs.",
+			v:    &[]User{{Name: " A "}, {Name: " B "}},
+			want: &[]User{{Name: "a"}, {Name: "b"}},
+		},
+		{
+			name: "Sanitizes a slice of structs.",
+			v:    []User{{Name: " A "}},
+			want: []User{{Name: "a"}},
+		},
+		{
+			name: "Sanitizes a slice of pointers to structs, skipping nil ones.",
+			v:    []*User{{Name: " A "}, nil},
+			want: []*User{{Name: "a"}, nil},
+		},
+		{
+			name: "Sanitizes a pointer to an array of structs.",
+			v:    &[2]User{{Name: " A "}, {Name: " B "}},
+			want: &[2]User{{Name: "a"}, {Name: "b"}},
+		},
+		{
+			name: "Sanitizes a map of structs.",
+			v:    map[string]User{"x": {Name: " A "}},
+			want: map[string]User{"x": {Name: "a"}},
+		},
+		{
+			name: "Sanitizes a pointer to a map of pointers to structs.",
+			v:    &map[int]*User{1: {Name: " A "}},
+			want: &map[int]*User{1: {Name: "a"}},
+		},
+		{
+			name: "Sanitizes a pointer to a pointer to a struct.",
+			v:    &userPtr,
+			want: &resUserPtr,
+		},
+		{
+			name: "Does nothing for a pointer to a string.",
+			v:    &str,
+			want: func() *string { v := " Hello "; return &v }(),
+		},
+		{
+			name: "Does nothing for a slice of strings.",
+			v:    []string{" a "},
+			want: []string{" a "},
+		},
+		{
+			name:    "Returns an error for nil.",
+			v:       nil,
+			wantErr: "can not sanitize nil",
+		},
+		{
+			name:    "Returns an error for a nil pointer to a struct.",
+			v:       nilUser,
+			wantErr: "can not sanitize a nil *sanitize.User",
+		},
+		{
+			name:    "Returns an error for a struct passed by value.",
+			v:       *user,
+			wantErr: "passed by value",
+		},
+		{
+			name:    "Returns an error for an array passed by value.",
+			v:       [1]User{},
+			wantErr: "passed by value",
+		},
+		{
+			name:    "Returns an error for a string passed by value.",
+			v:       "hello",
+			wantErr: "passed by value",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.Sanitize(tt.v)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Sanitize() - got error %v, wanted it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Sanitize() - got unexpected error %v", err)
+			}
+			if !reflect.DeepEqual(tt.v, tt.want) {
+				t.Errorf("Sanitize() - got %+v but wanted %+v", tt.v, tt.want)
+			}
+		})
+	}
+}
+
+func Test_Sanitize_DefZero(t *testing.T) {
+	type TestDefZeroSub struct {
+		Name string `san:"def=anonymous"`
+	}
+	type TestDefZero struct {
+		Name   string   `san:"trim,def=unknown,defzero"`
+		Age    int      `san:"min=18,def=21,defzero"`
+		Score  float64  `san:"def=0.5,defzero"`
+		Active bool     `san:"def=true,defzero"`
+		Email  *string  `san:"def=none,defzero"`
+		Tags   []string `san:"trim,def=misc,defzero"`
+		Plain  string   `san:"def=plain"`
+		Subs   []TestDefZeroSub
+	}
+
+	empty := ""
+	tests := []struct {
+		name    string
+		options []Option
+		v       *TestDefZero
+		want    *TestDefZero
+	}{
+		{
+			name: "Sets the default of zero fields with defzero.",
+			v: &TestDefZero{
+				Name:  "   ",
+				Email: &empty,
+				Tags:  []string{"", " a ", " "},
+				Subs:  []TestDefZeroSub{{}},
+			},
+			want: &TestDefZero{
+				Name:   "unknown",
+				Age:    21,
+				Score:  0.5,
+				Active: true,
+				Email:  func() *string { v := "none"; return &v }(),
+				Tags:   []string{"misc", "a", "misc"},
+				Subs:   []TestDefZeroSub{{}},
+			},
+		},
+		{
+			name: "Keeps non-zero fields, which are sanitized as usual.",
+			v:    &TestDefZero{Name: " x ", Age: 5, Score: 2, Active: true, Email: func() *string { v := "a"; return &v }()},
+			want: &TestDefZero{Name: "x", Age: 18, Score: 2, Active: true, Email: func() *string { v := "a"; return &v }()},
+		},
+		{
+			name:    "Sets the default of every zero field with def with OptionDefZero.",
+			options: []Option{OptionDefZero{Value: true}},
+			v:       &TestDefZero{Subs: []TestDefZeroSub{{}, {Name: "x"}}},
+			want: &TestDefZero{
+				Name:   "unknown",
+				Age:    21,
+				Score:  0.5,
+				Active: true,
+				Email:  func() *string { v := "none"; return &v }(),
+				Plain:  "plain",
+				Subs:   []TestDefZeroSub{{Name: "anonymous"}, {Name: "x"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := New(tt.options...)
+			if err := s.Sanitize(tt.v); err != nil {
+				t.Fatalf("Sanitize() - got unexpected error %v", err)
+			}
+			if !reflect.DeepEqual(tt.v, tt.want) {
+				t.Errorf("Sanitize() - got %+v but wanted %+v", tt.v, tt.want)
+			}
+		})
+	}
+}
+
+func Test_Sanitize_DefZeroWithoutDef(t *testing.T) {
+	type TestDefZeroWithoutDef struct {
+		Name string `san:"trim,defzero"`
+	}
+
+	s, _ := New()
+
+	err := s.Sanitize(&TestDefZeroWithoutDef{})
+	if err == nil || err.Error() != `field "Name" (defzero): defzero needs a def tag component` {
+		t.Errorf("Sanitize() - got error %v, wanted a defzero error", err)
+	}
+}
+
+func Test_Sanitize_SliceNilElements(t *testing.T) {
+	type TestSliceNil struct {
+		Names  []*string  `san:"trim,def=none"`
+		Ages   []*int     `san:"min=1,def=18"`
+		Scores []*float64 `san:"dropnil,max=10"`
+		Limits []*uint    `san:"dropnil,maxsize=2"`
+		Tags   *[]*string `san:"dropnil,trim"`
+	}
+
+	str := func(v string) *string { return &v }
+	num := func(v int) *int { return &v }
+	flt := func(v float64) *float64 { return &v }
+	unum := func(v uint) *uint { return &v }
+
+	v := &TestSliceNil{
+		Names:  []*string{str(" a "), nil, str(" b "), nil},
+		Ages:   []*int{nil, num(0), nil, num(30)},
+		Scores: []*float64{nil, flt(20), nil, flt(5), nil},
+		Limits: []*uint{nil, unum(1), nil, unum(2), unum(3)},
+		Tags:   &[]*string{nil, str(" x ")},
+	}
+
+	s, _ := New()
+	if err := s.Sanitize(v); err != nil {
+		t.Fatalf("Sanitize() - got unexpected error %v", err)
+	}
+
+	want := &TestSliceNil{
+		Names:  []*string{str("a"), str("none"), str("b"), str("none")},
+		Ages:   []*int{num(18), num(1), num(18), num(30)},
+		Scores: []*float64{flt(10), flt(5)},
+		Limits: []*uint{unum(1), unum(2)},
+		Tags:   &[]*string{str("x")},
+	}
+	if !reflect.DeepEqual(v, want) {
+		t.Errorf("Sanitize() - got %+v but wanted %+v", v, want)
+	}
+	if v.Ages[0] == v.Ages[2] {
+		t.Errorf("Sanitize() - nil elements share the address of their default")
+	}
+
+	if err := s.SanitizeValue([]*string{nil}, "dropnil"); err == nil {
+		t.Errorf("SanitizeValue() - did not receive expected error for a slice passed by value")
+	}
+
+	type TestBadDropNil struct {
+		Names []string `san:"dropnil"`
+	}
+	err := s.Sanitize(&TestBadDropNil{})
+	if err == nil || !strings.Contains(err.Error(), "dropnil can not be used on []string") {
+		t.Errorf("Sanitize() - got error %v, wanted a dropnil error", err)
+	}
+}
+
+func Test_Sanitize_TagOrder(t *testing.T) {
+	type TestStruct struct {
+		Truncated string  `san:"max=5,trim"`
+		Lowered   string  `san:"lower,capfirst"`
+		Capped    string  `san:"capfirst,lower"`
+		Def       *string `san:"def=x,capfirst"`
+		Escaped   string  `san:"html=escape,maxbytes=4"`
+		Names     []string
+		Sub       *TestStruct
+	}
+
+	fixed, _ := New(OptionSanitizerFunc{Name: "capfirst", Sanitizer: capFirst})
+	ordered, _ := New(
+		OptionSanitizerFunc{Name: "capfirst", Sanitizer: capFirst},
+		OptionTagOrder{Value: true},
+	)
+
+	newStruct := func() *TestStruct {
+		def := "y"
+		return &TestStruct{
+			Truncated: "  hello world",
+			Lowered:   "HELLO",
+			Capped:    "HELLO",
+			Def:       &def,
+			Escaped:   "<b>",
+		}
+	}
+
+	v := newStruct()
+	if err := fixed.Sanitize(v); err != nil {
+		t.Fatalf("Sanitize() - got unexpected error %v", err)
+	}
+	want := &TestStruct{Truncated: "hello", Lowered: "hello", Capped: "hello", Escaped: "&lt;b&gt;"}
+	want.Def = v.Def
+	if !reflect.DeepEqual(v, want) || *v.Def != "Y" {
+		t.Errorf("Sanitize() - got %+v but wanted %+v", v, want)
+	}
+
+	v = newStruct()
+	v.Def = nil
+	v.Sub = newStruct()
+	if err := ordered.Sanitize(v); err != nil {
+		t.Fatalf("Sanitize() - got unexpected error %v", err)
+	}
+	for _, got := range []*TestStruct{v, v.Sub} {
+		if got.Truncated != "hel" || got.Lowered != "Hello" || got.Capped != "hello" ||
+			got.Def == nil || got.Escaped != "&lt;" {
+			t.Errorf("Sanitize() - got %+v with tag order", got)
+		}
+	}
+	if *v.Def != "X" || *v.Sub.Def != "Y" {
+		t.Errorf("Sanitize() - got defaults %q and %q with tag order", *v.Def, *v.Sub.Def)
+	}
+
+	name := "  hello"
+	if err := ordered.SanitizeValue(&name, "max=5,trim,upper"); err != nil || name != "HEL" {
+		t.Errorf("SanitizeValue() - got %q and error %v with tag order", name, err)
+	}
+}
+
+func Test_Sanitize_TagOrderCustomSplit(t *testing.T) {
+	type TestItem struct {
+		Name string `san:"wrap=!"`
+	}
+	type TestStruct struct {
+		Cut   string     `san:"max=3,a,suffix=~"`
+		Def   string     `san:"def=x,a,defzero"`
+		Items []TestItem `san:"maxsize=3,keep,maxsize=2"`
+	}
+	type TestBad struct {
+		Age int `san:"max=1,keep,min=5"`
+	}
+
+	keep := func(s Sanitizer, structValue reflect.Value, idx int) error {
+		return nil
+	}
+	s, err := New(append(typedOptions,
+		OptionSanitizerFunc{Name: "a", Sanitizer: appendSanitizer("a")},
+		OptionSanitizerFunc{Name: "keep", Sanitizer: keep},
+		OptionTagOrder{Value: true},
+	)...)
+	if err != nil {
+		t.Fatalf("New() - got unexpected error %v", err)
+	}
+
+	// The components around a custom sanitizer are checked together
+	if err := s.Validate(TestStruct{}); err != nil {
+		t.Errorf("Validate() - got unexpected error %v", err)
+	}
+	if err := s.Validate(TestBad{}); err == nil {
+		t.Errorf("Validate() - did not receive expected error for max below min")
+	}
+	if err := s.Sanitize(&TestBad{Age: 3}); err == nil {
+		t.Errorf("Sanitize() - did not receive expected error for max below min")
+	}
+
+	// Elements are sanitized only once
+	v := &TestStruct{Cut: "abcdef", Items: []TestItem{{Name: "a"}, {Name: "b"}, {Name: "c"}}}
+	if err := s.Sanitize(v); err != nil {
+		t.Fatalf("Sanitize() - got unexpected error %v", err)
+	}
+	want := &TestStruct{Cut: "ab~a", Def: "xa", Items: []TestItem{{Name: "!a!"}, {Name: "!b!"}}}
+	if !reflect.DeepEqual(v, want) {
+		t.Errorf("Sanitize() - got %+v but wanted %+v", v, want)
+	}
+}
+
+// appendSanitizer returns a custom sanitizer that appends suffix to a string
+// field.
+func appendSanitizer(suffix string) SanitizerFunc {
+	return func(s Sanitizer, structValue reflect.Value, idx int) error {
+		fieldValue := structValue.Field(idx)
+		fieldValue.SetString(fieldValue.String() + suffix)
+		return nil
+	}
+}
+
+func Test_Sanitize_CustomSanitizerOrder(t *testing.T) {
+	type TestStruct struct {
+		AB    string `san:"a,b"`
+		BA    string `san:"b,a,b"`
+		Upper string `san:"upper,a,after"`
+		Max   string `san:"after,max=3,a"`
+	}
+
+	// Custom sanitizers used to run in random order, so a single run could
+	// pass by chance
+	for i := 0; i < 20; i++ {
+		s, _ := New(
+			OptionSanitizerFunc{Name: "a", Sanitizer: appendSanitizer("a")},
+			OptionSanitizerFunc{Name: "b", Sanitizer: appendSanitizer("b")},
+			OptionSanitizerFunc{Name: "after", Sanitizer: appendSanitizer("z"), After: true},
+		)
+
+		v := &TestStruct{Upper: "x", Max: "xyz"}
+		if err := s.Sanitize(v); err != nil {
+			t.Fatalf("Sanitize() - got unexpected error %v", err)
+		}
+		want := &TestStruct{AB: "ab", BA: "ba", Upper: "XAz", Max: "xyzz"}
+		if !reflect.DeepEqual(v, want) {
+			t.Fatalf("Sanitize() - got %+v but wanted %+v", v, want)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package sanitize
 
 import (
+	"fmt"
 	"reflect"
 	"strconv"
 )
@@ -11,22 +12,111 @@ import (
 func sanitizeSliceField(s Sanitizer, structValue reflect.Value, idx int) error {
 	fieldValue := structValue.Field(idx)
 
-	tags := s.fieldTags(structValue.Type().Field(idx).Tag)
+	tags, err := s.fieldTags(structValue.Type().Field(idx).Tag)
+	if err != nil {
+		return err
+	}
 
-	if fieldValue.Kind() == reflect.Ptr && !fieldValue.IsNil() {
+	if fieldValue.Kind() == reflect.Ptr {
+		if fieldValue.IsNil() {
+			return nil
+		}
 		fieldValue = fieldValue.Elem()
 	}
 
+	rule, err := compileSliceRule(fieldValue.Type(), tags)
+	if err != nil {
+		return err
+	}
+	return rule.apply(fieldValue)
+}
+
+// sliceRule is the compiled form of the tag components that apply to a slice
+// itself rather than to its elements.
+type sliceRule struct {
+	dropNil    bool
+	hasMaxsize bool
+	maxsize    int
+}
+
+// compileSliceRule parses the tag components of the slice type t once.
+func compileSliceRule(t reflect.Type, tags map[string]string) (*sliceRule, error) {
+	rule := &sliceRule{}
+
+	if _, ok := tags["dropnil"]; ok {
+		switch t.Elem().Kind() {
+		case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan:
+			rule.dropNil = true
+		default:
+			return nil, tagError("dropnil", fmt.Errorf("dropnil can not be used on %s, its elements can not be nil", t))
+		}
+	}
+
 	if _, ok := tags["maxsize"]; ok {
 		max, err := strconv.ParseInt(tags["maxsize"], 10, 32)
 		if err != nil {
+			return nil, tagError("maxsize", err)
+		}
+		if max < 0 {
+			return nil, tagError("maxsize", fmt.Errorf("maxsize can not be below 0"))
+		}
+		rule.hasMaxsize = true
+		rule.maxsize = int(max)
+	}
+
+	return rule, nil
+}
+
+// isEmpty reports whether r leaves every slice as it is.
+func (r *sliceRule) isEmpty() bool {
+	return !r.dropNil && !r.hasMaxsize
+}
+
+// apply removes the nil elements of v with dropnil, and then truncates it to
+// maxsize, so that maxsize counts the elements that are kept.
+func (r *sliceRule) apply(v reflect.Value) error {
+	if r.dropNil {
+		if err := dropNil(v); err != nil {
 			return err
 		}
-		if fieldValue.Len() < int(max) {
-			return nil
+	}
+	if r.hasMaxsize && v.Len() > r.maxsize {
+		if !v.CanSet() {
+			return tagError("maxsize", fmt.Errorf("can not resize a %s passed by value, pass a pointer to it instead", v.Type()))
+		}
+		v.Set(v.Slice(0, r.maxsize))
+	}
+	return nil
+}
+
+// dropNil removes the nil elements of the slice v, keeping the order of the
+// others.
+func dropNil(v reflect.Value) error {
+	n := 0
+	for i := 0; i < v.Len(); i++ {
+		if !v.Index(i).IsNil() {
+			n++
 		}
-		fieldValue.Set(fieldValue.Slice(0, int(max)))
+	}
+	if n == v.Len() {
+		return nil
+	}
+	if !v.CanSet() {
+		return tagError("dropnil", fmt.Errorf("can not resize a %s passed by value, pass a pointer to it instead", v.Type()))
 	}
 
+	kept := 0
+	for i := 0; i < v.Len(); i++ {
+		if elem := v.Index(i); !elem.IsNil() {
+			v.Index(kept).Set(elem)
+			kept++
+		}
+	}
+	// Clear the dropped tail so that the backing array does not keep what
+	// the moved elements point to alive
+	for i := kept; i < v.Len(); i++ {
+		v.Index(i).Set(reflect.Zero(v.Type().Elem()))
+	}
+	v.SetLen(kept)
 	return nil
 }
@@ -1,9 +1,10 @@
 package sanitize
 
 import (
+	"fmt"
 	"reflect"
 	"regexp"
-	"strconv"
+	"sort"
 	"strings"
 	"time"
 )
@@ -12,92 +13,192 @@ import (
 // reflect.Value for the struct because it needs access to both the Value and
 // Type of the struct.
 func sanitizeStrField(s Sanitizer, structValue reflect.Value, idx int) error {
-	fieldValue := structValue.Field(idx)
+	return s.sanitizeScalarField(structValue, idx, compileStrRule)
+}
+
+// strTransform is a step of a string rule, along with the tag component it
+// comes from. custom is set instead of fn for typed custom sanitizers, which
+// may fail.
+type strTransform struct {
+	tag    string
+	fn     func(string) string
+	custom func(string) (string, error)
+	phase  int
+}
 
-	tags := s.fieldTags(structValue.Type().Field(idx).Tag)
+// compileStrRule turns the tag components of a string field into a list of
+// transforms, applied in a fixed order no matter how the tag is written, or
+// in the order of the tag with OptionTagOrder.
+func compileStrRule(s *Sanitizer, t reflect.Type, tags map[string]string, order []string) (*scalarRule, error) {
+	var transforms []strTransform
 
-	if fieldValue.Kind() == reflect.Ptr && !fieldValue.IsNil() {
-		fieldValue = fieldValue.Elem()
+	// Typed custom sanitizers run before or after the built-in components,
+	// which may have been overridden by them
+	before, after := s.typedSteps(t, tags, order)
+	tags = s.builtinTags(tags)
+	for _, step := range before {
+		transforms = append(transforms, step.strTransform())
 	}
 
-	isSlice := fieldValue.Kind() == reflect.Slice
+	// Let's strip out invalid characters before anything else
+	if _, ok := tags["xss"]; ok {
+		transforms = append(transforms, strTransform{tag: "xss", fn: xss})
+	}
 
-	var fields []reflect.Value
-	if !isSlice {
-		fields = []reflect.Value{fieldValue}
-	} else {
-		for i := 0; i < fieldValue.Len(); i++ {
-			fields = append(fields, fieldValue.Index(i))
+	// Whitespace and control characters are cleaned up next, so that trim
+	// sees the spaces that collapse leaves at either end
+	if v, ok := tags["nocontrol"]; ok {
+		noControl, err := compileNoControl(v)
+		if err != nil {
+			return nil, err
 		}
+		transforms = append(transforms, strTransform{tag: "nocontrol", fn: noControl})
+	}
+	if err := checkConflicts(tags, []string{"newlines", "collapse"}); err != nil {
+		return nil, err
+	}
+	if v, ok := tags["newlines"]; ok {
+		replacer, ok := newlineReplacers[v]
+		if !ok {
+			return nil, tagError("newlines", fmt.Errorf("unknown newlines %q, expected lf or crlf", v))
+		}
+		transforms = append(transforms, strTransform{tag: "newlines", fn: replacer.Replace})
+	}
+	if _, ok := tags["collapse"]; ok {
+		transforms = append(transforms, strTransform{tag: "collapse", fn: collapseSpace})
 	}
 
-	for _, field := range fields {
-		isPtr := field.Kind() == reflect.Ptr
-		if isPtr && field.IsNil() {
-			// Only handle "def" if it is present, then finish san.
-			if _, ok := tags["def"]; ok {
-				defStr := tags["def"]
-				field.Set(reflect.ValueOf(&defStr))
-			}
+	// Trim must happen before the other tags, no matter what other
+	// components there are.
+	if trimset, ok := tags["trim"]; ok {
+		if len(trimset) == 0 {
+			trimset = " "
+		}
+		transforms = append(transforms, strTransform{tag: "trim", fn: func(v string) string {
+			return strings.Trim(v, trimset)
+		}})
+	}
+	if trimset, ok := tags["trimleft"]; ok {
+		if len(trimset) == 0 {
+			trimset = " "
+		}
+		transforms = append(transforms, strTransform{tag: "trimleft", fn: func(v string) string {
+			return strings.TrimLeft(v, trimset)
+		}})
+	}
+	if trimset, ok := tags["trimright"]; ok {
+		if len(trimset) == 0 {
+			trimset = " "
+		}
+		transforms = append(transforms, strTransform{tag: "trimright", fn: func(v string) string {
+			return strings.TrimRight(v, trimset)
+		}})
+	}
 
-			return nil
+	// Normalization comes right after trim, so that the components that
+	// compare, count or change characters all see the same form
+	if err := checkConflicts(tags, stringNormTags); err != nil {
+		return nil, err
+	}
+	for _, name := range stringNormTags {
+		if _, ok := tags[name]; ok {
+			transforms = append(transforms, strTransform{tag: name, fn: normForms[name].String})
 		}
+	}
+	if _, ok := tags["ascii"]; ok {
+		transforms = append(transforms, strTransform{tag: "ascii", fn: asciiFold})
+	}
 
-		if isPtr && !field.IsNil() {
-			// Dereference then continue as normal.
-			field = field.Elem()
+	// Apply rest of transforms
+	if _, ok := tags["date"]; ok {
+		in, keepFormat, out := s.dateInput, s.dateKeepFormat, s.dateOutput
+		transforms = append(transforms, strTransform{tag: "date", fn: func(v string) string {
+			return date(in, keepFormat, out, v)
+		}})
+	}
+	trunc, err := compileTruncator(tags)
+	if err != nil {
+		return nil, err
+	}
+	if trunc != nil {
+		// Truncation takes the place of whichever of max and maxbytes comes
+		// first
+		tag := "max"
+		if _, ok := tags["max"]; !ok || tagIndex(order, "maxbytes") < tagIndex(order, "max") {
+			tag = "maxbytes"
 		}
+		transforms = append(transforms, strTransform{tag: tag, fn: trunc.truncate})
+	}
+	if _, ok := tags["lower"]; ok {
+		transforms = append(transforms, strTransform{tag: "lower", fn: strings.ToLower})
+	}
+	if _, ok := tags["upper"]; ok {
+		transforms = append(transforms, strTransform{tag: "upper", fn: strings.ToUpper})
+	}
+	if _, ok := tags["title"]; ok {
+		transforms = append(transforms, strTransform{tag: "title", fn: toTitle})
+	}
+	if _, ok := tags["cap"]; ok {
+		transforms = append(transforms, strTransform{tag: "cap", fn: toCap})
+	}
 
-		// Let's strip out invalid characters before anything else
-		if _, ok := tags["xss"]; ok {
-			oldStr := field.String()
-			field.SetString(xss(oldStr))
+	// HTML comes last, so that no other component can break the markup it
+	// produces
+	if v, ok := tags["html"]; ok {
+		sanitizeHTML, err := s.compileHTML(v)
+		if err != nil {
+			return nil, err
 		}
+		transforms = append(transforms, strTransform{tag: "html", fn: sanitizeHTML})
+	}
 
-		// Trim must happen before the other tags, no matter what other
-		// components there are.
-		if trimset, ok := tags["trim"]; ok {
-			if len(trimset) == 0 {
-				trimset = " "
-			}
-			oldStr := field.String()
-			field.SetString(strings.Trim(oldStr, trimset))
+	for _, step := range after {
+		transforms = append(transforms, step.strTransform())
+	}
+
+	if s.tagOrder {
+		sort.SliceStable(transforms, func(i, j int) bool {
+			return tagIndex(order, transforms[i].tag) < tagIndex(order, transforms[j].tag)
+		})
+		for i := range transforms {
+			transforms[i].phase = s.phaseAt(order, tagIndex(order, transforms[i].tag))
 		}
+	}
+
+	rule := &scalarRule{}
 
-		// Apply rest of transforms
-		if _, ok := tags["date"]; ok {
+	if len(transforms) > 0 {
+		rule.apply = func(field reflect.Value, phase int) error {
 			oldStr := field.String()
-			field.SetString(date(s.dateInput, s.dateKeepFormat, s.dateOutput, oldStr))
-		}
-		if _, ok := tags["max"]; ok {
-			max, err := strconv.ParseInt(tags["max"], 10, 32)
-			if err != nil {
-				return err
+			newStr := oldStr
+			for _, transform := range transforms {
+				if !inPhase(phase, transform.phase) {
+					continue
+				}
+				if transform.custom == nil {
+					newStr = transform.fn(newStr)
+					continue
+				}
+				var err error
+				if newStr, err = transform.custom(newStr); err != nil {
+					return tagError(transform.tag, err)
+				}
 			}
-			oldStr := field.String()
-			if max < int64(len(oldStr)) {
-				field.SetString(oldStr[0:max])
+			if newStr != oldStr {
+				field.SetString(newStr)
 			}
+			return nil
 		}
-		if _, ok := tags["lower"]; ok {
-			oldStr := field.String()
-			field.SetString(strings.ToLower(oldStr))
-		}
-		if _, ok := tags["upper"]; ok {
-			oldStr := field.String()
-			field.SetString(strings.ToUpper(oldStr))
-		}
-		if _, ok := tags["title"]; ok {
-			oldStr := field.String()
-			field.SetString(toTitle(oldStr))
-		}
-		if _, ok := tags["cap"]; ok {
-			oldStr := field.String()
-			field.SetString(toCap(oldStr))
+	}
+
+	if def, ok := tags["def"]; ok {
+		rule.def = func(field reflect.Value) error {
+			field.SetString(def)
+			return nil
 		}
 	}
 
-	return nil
+	return rule, nil
 }
 
 func toTitle(s string) string {
@@ -1,32 +1,289 @@
 package sanitize
 
 import (
+	"fmt"
 	"reflect"
 	"strings"
 )
 
-func (s Sanitizer) fieldTags(f reflect.StructTag) map[string]string {
-	m := make(map[string]string)
+func (s Sanitizer) fieldTags(f reflect.StructTag) (map[string]string, error) {
+	comps, err := s.fieldTagComponents(f)
+	if err != nil {
+		return nil, err
+	}
+	return tagComponents(comps), nil
+}
 
+// fieldTagComponents returns the components of the tag f in the order they
+// are written.
+func (s Sanitizer) fieldTagComponents(f reflect.StructTag) ([]tagComponent, error) {
 	tStr, ok := f.Lookup(s.tagName)
 	if !ok {
 		// No tag so no sanitization to do
-		return m
+		return nil, nil
 	}
 
 	// tag present - process tag string into key-value pairs (ex.
 	// min=1 and max=10). Note: some have no value
-	comps := strings.Split(tStr, ",")
+	return parseTag(tStr, ',')
+}
+
+// tagComponent is a single component of a tag, such as max=10 or trim.
+type tagComponent struct {
+	name  string
+	value string
+}
+
+// tagComponents turns the components of a tag into key-value pairs. Later
+// components replace earlier ones with the same name.
+func tagComponents(comps []tagComponent) map[string]string {
+	m := make(map[string]string, len(comps))
 	for _, comp := range comps {
-		if strings.Contains(comp, "=") {
-			// Use as param. Ex. 'max' with value '42'
-			kv := strings.Split(comp, "=")
-			m[kv[0]] = kv[1]
-		} else {
-			// Use directly. Ex. 'trim' without value
-			m[comp] = ""
+		m[comp.name] = comp.value
+	}
+	return m
+}
+
+// tagNames returns the names of comps in the order they are written.
+func tagNames(comps []tagComponent) []string {
+	order := make([]string, len(comps))
+	for i, comp := range comps {
+		order[i] = comp.name
+	}
+	return order
+}
+
+// tagIndex returns the position of the component name in order. Components
+// that are written more than once take the position of the last one, since
+// it is the one that is used, and missing ones come after all the others.
+func tagIndex(order []string, name string) int {
+	for i := len(order) - 1; i >= 0; i-- {
+		if order[i] == name {
+			return i
 		}
 	}
+	return len(order)
+}
 
-	return m
+// phaseAt returns the phase of the component at position i in order, which is
+// the number of custom sanitizers written before it. It is always 0 without
+// OptionTagOrder. See allPhases.
+func (s *Sanitizer) phaseAt(order []string, i int) int {
+	if !s.tagOrder {
+		return 0
+	}
+	phase := 0
+	for _, name := range order[:i] {
+		if _, ok := s.sanitizersByName[name]; ok {
+			phase++
+		}
+	}
+	return phase
+}
+
+// firstPhase returns the earliest phase of the components of tags named
+// names, or of all the built-in components of tags but def and defzero when
+// names is empty. Components that are missing from tags are ignored.
+func (s *Sanitizer) firstPhase(order []string, tags map[string]string, names ...string) int {
+	return s.phaseAt(order, s.firstIndex(order, tags, names...))
+}
+
+// firstIndex is like firstPhase, but returns the position of the component
+// in order, or len(order) when there is none.
+func (s *Sanitizer) firstIndex(order []string, tags map[string]string, names ...string) int {
+	first := len(order)
+	for name := range tags {
+		if len(names) > 0 && !hasTag(names, name) {
+			continue
+		}
+		if len(names) == 0 && (name == "def" || name == "defzero" || !isBuiltinTag(name) || s.overrides[name]) {
+			continue
+		}
+		if i := tagIndex(order, name); i < first {
+			first = i
+		}
+	}
+	return first
+}
+
+// parseTag splits tag into components separated by sep, such as "max=10" and
+// "trim". Only the first "=" separates the name from the value, so values of
+// components holding other components (ex. keys=trim|max=10) are kept whole.
+//
+// A value that starts with a single quote runs up to the next one, separators
+// included (ex. def='a,b'). A backslash escapes the separator, a single quote
+// or another backslash, inside quotes or not (ex. trim=\,;). Other
+// backslashes are kept as they are, so that values such as regular
+// expressions do not need to be escaped. Empty components are ignored.
+func parseTag(tag string, sep byte) ([]tagComponent, error) {
+	var comps []tagComponent
+	for len(tag) > 0 {
+		comp, rest, err := parseTagComponent(tag, sep)
+		if err != nil {
+			return nil, err
+		}
+		if comp != nil {
+			comps = append(comps, *comp)
+		}
+		tag = rest
+	}
+	return comps, nil
+}
+
+// parseTagComponent parses the first component of tag, and returns it along
+// with the rest of tag after its separator. comp is nil if the component is
+// empty.
+func parseTagComponent(tag string, sep byte) (comp *tagComponent, rest string, err error) {
+	name, i := scanTagText(tag, 0, sep, string(sep)+"=")
+	if i == len(tag) || tag[i] == sep {
+		if name == "" {
+			return nil, afterSep(tag, i), nil
+		}
+		return &tagComponent{name: name}, afterSep(tag, i), nil
+	}
+	if name == "" {
+		raw := tag
+		if j := strings.IndexByte(tag, sep); j >= 0 {
+			raw = tag[:j]
+		}
+		return nil, "", fmt.Errorf("tag component %q has no name", raw)
+	}
+
+	// Skip the "="
+	i++
+	if i == len(tag) || tag[i] != '\'' {
+		value, j := scanTagText(tag, i, sep, string(sep))
+		return &tagComponent{name: name, value: value}, afterSep(tag, j), nil
+	}
+
+	value, j := scanTagText(tag, i+1, sep, "'")
+	if j == len(tag) {
+		return nil, "", tagError(name, fmt.Errorf("unterminated quote in the value of tag component %q", name))
+	}
+	j++
+	if j < len(tag) && tag[j] != sep {
+		extra := tag[j:]
+		if k := strings.IndexByte(extra, sep); k >= 0 {
+			extra = extra[:k]
+		}
+		return nil, "", tagError(name, fmt.Errorf(
+			"unexpected %q after the quoted value of tag component %q",
+			extra,
+			name,
+		))
+	}
+	return &tagComponent{name: name, value: value}, afterSep(tag, j), nil
+}
+
+// scanTagText reads tag from i up to the first unescaped byte in stops, and
+// returns the unescaped text along with the index it stopped at.
+func scanTagText(tag string, i int, sep byte, stops string) (string, int) {
+	var b strings.Builder
+	for ; i < len(tag); i++ {
+		c := tag[i]
+		if c == '\\' && i+1 < len(tag) {
+			if n := tag[i+1]; n == '\\' || n == '\'' || n == sep {
+				b.WriteByte(n)
+				i++
+				continue
+			}
+		}
+		if strings.IndexByte(stops, c) >= 0 {
+			break
+		}
+		b.WriteByte(c)
+	}
+	return b.String(), i
+}
+
+// afterSep returns what is left of tag after the separator at i, if any.
+func afterSep(tag string, i int) string {
+	if i >= len(tag) {
+		return ""
+	}
+	return tag[i+1:]
+}
+
+// Built-in tag components, by the kind of value they apply to.
+var (
+	stringTags = []string{"xss", "nocontrol", "newlines", "collapse", "trim", "trimleft", "trimright", "nfc", "nfd", "nfkc", "nfkd", "ascii", "date", "max", "maxbytes", "cut", "suffix", "lower", "upper", "title", "cap", "html", "def", "defzero"}
+	intTags    = []string{"min", "max", "def", "abs", "multiple", "floor", "ceil", "trunc", "rounding", "nonzero", "defzero"}
+	floatTags  = []string{"min", "max", "def", "round", "step", "floor", "ceil", "trunc", "rounding", "nonan", "finite", "defzero"}
+	boolTags   = []string{"def", "defzero"}
+	sliceTags  = []string{"maxsize", "dropnil"}
+	mapTags    = []string{"keys"}
+
+	// stringCaseTags change the case of the whole string, so only one of
+	// them can have an effect.
+	stringCaseTags = []string{"lower", "upper", "title", "cap"}
+
+	// stringNormTags are the Unicode normalization forms, only one of them
+	// can be used.
+	stringNormTags = []string{"nfc", "nfd", "nfkc", "nfkd"}
+
+	// floatPrecisionTags and roundingDirectionTags set the precision and the
+	// direction of rounding, only one of each can be used.
+	floatPrecisionTags    = []string{"round", "step"}
+	roundingDirectionTags = []string{"floor", "ceil", "trunc", "rounding"}
+
+	// floatNonFiniteTags catch NaN, or NaN and infinities, so only one of
+	// them can be used.
+	floatNonFiniteTags = []string{"nonan", "finite"}
+)
+
+var scalarTags = map[reflect.Kind][]string{
+	reflect.String:  stringTags,
+	reflect.Int:     intTags,
+	reflect.Int8:    intTags,
+	reflect.Int16:   intTags,
+	reflect.Int32:   intTags,
+	reflect.Int64:   intTags,
+	reflect.Uint:    intTags,
+	reflect.Uint8:   intTags,
+	reflect.Uint16:  intTags,
+	reflect.Uint32:  intTags,
+	reflect.Uint64:  intTags,
+	reflect.Float32: floatTags,
+	reflect.Float64: floatTags,
+	reflect.Bool:    boolTags,
+}
+
+// isBuiltinTag reports whether name is a built-in tag component of any type.
+func isBuiltinTag(name string) bool {
+	for _, names := range scalarTags {
+		if hasTag(names, name) {
+			return true
+		}
+	}
+	return hasTag(sliceTags, name) || hasTag(mapTags, name)
+}
+
+// builtinTags returns tags without the components whose built-in
+// implementation is overridden by a custom sanitizer, for the built-in
+// compilers to ignore them.
+func (s *Sanitizer) builtinTags(tags map[string]string) map[string]string {
+	if len(s.overrides) == 0 {
+		return tags
+	}
+	builtins := make(map[string]string, len(tags))
+	for name, v := range tags {
+		if !s.overrides[name] {
+			builtins[name] = v
+		}
+	}
+	return builtins
+}
+
+// checkDisabled returns an error if tags holds a built-in component that is
+// disabled with OptionDisableTags.
+func (s *Sanitizer) checkDisabled(tags map[string]string) error {
+	if len(s.disabled) == 0 {
+		return nil
+	}
+	for _, name := range s.disabled {
+		if _, ok := tags[name]; ok {
+			return tagError(name, fmt.Errorf("tag component %q is disabled", name))
+		}
+	}
+	return nil
 }
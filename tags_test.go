@@ -0,0 +1,278 @@
+package sanitize
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_parseTag(t *testing.T) {
+	tests := []struct {
+		name    string
+		tag     string
+		sep     byte
+		want    []tagComponent
+		wantErr string
+	}{
+		{
+			name: "Parses components with and without values.",
+			tag:  "trim,max=10",
+			sep:  ',',
+			want: []tagComponent{{name: "trim"}, {name: "max", value: "10"}},
+		},
+		{
+			name: "Keeps everything after the first equal sign.",
+			tag:  "def=x=y,keys=trim|max=10",
+			sep:  ',',
+			want: []tagComponent{{name: "def", value: "x=y"}, {name: "keys", value: "trim|max=10"}},
+		},
+		{
+			name: "Keeps empty values.",
+			tag:  "def=,trim",
+			sep:  ',',
+			want: []tagComponent{{name: "def"}, {name: "trim"}},
+		},
+		{
+			name: "Ignores empty components.",
+			tag:  ",trim,,lower,",
+			sep:  ',',
+			want: []tagComponent{{name: "trim"}, {name: "lower"}},
+		},
+		{
+			name: "Ignores an empty tag.",
+			tag:  "",
+			sep:  ',',
+		},
+		{
+			name: "Keeps separators in quoted values.",
+			tag:  "def='a,b',trim",
+			sep:  ',',
+			want: []tagComponent{{name: "def", value: "a,b"}, {name: "trim"}},
+		},
+		{
+			name: "Keeps empty quoted values.",
+			tag:  "def=''",
+			sep:  ',',
+			want: []tagComponent{{name: "def"}},
+		},
+		{
+			name: "Unescapes quotes and backslashes in quoted values.",
+			tag:  `def='it\'s \\ here'`,
+			sep:  ',',
+			want: []tagComponent{{name: "def", value: `it's \ here`}},
+		},
+		{
+			name: "Unescapes separators outside quotes.",
+			tag:  `trim=\,;,def=a\,b`,
+			sep:  ',',
+			want: []tagComponent{{name: "trim", value: ",;"}, {name: "def", value: "a,b"}},
+		},
+		{
+			name: "Keeps quotes that do not start the value.",
+			tag:  "def=it's",
+			sep:  ',',
+			want: []tagComponent{{name: "def", value: "it's"}},
+		},
+		{
+			name: "Keeps other backslashes as they are.",
+			tag:  `def=\d+\.\d,trim=\`,
+			sep:  ',',
+			want: []tagComponent{{name: "def", value: `\d+\.\d`}, {name: "trim", value: `\`}},
+		},
+		{
+			name: "Leaves escapes of other separators to nested tags.",
+			tag:  `trim=\,|lower`,
+			sep:  '|',
+			want: []tagComponent{{name: "trim", value: `\,`}, {name: "lower"}},
+		},
+		{
+			name:    "Rejects components without a name.",
+			tag:     "trim,=10",
+			sep:     ',',
+			wantErr: `tag component "=10" has no name`,
+		},
+		{
+			name:    "Rejects unterminated quotes.",
+			tag:     "def='a,b",
+			sep:     ',',
+			wantErr: `(def): unterminated quote in the value of tag component "def"`,
+		},
+		{
+			name:    "Rejects text after a quoted value.",
+			tag:     "def='a'b,trim",
+			sep:     ',',
+			wantErr: `unexpected "b" after the quoted value of tag component "def"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseTag(tt.tag, tt.sep)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("parseTag(%q) - got error %v, wanted %q", tt.tag, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseTag(%q) - got unexpected error %v", tt.tag, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseTag(%q) - got %+v but wanted %+v", tt.tag, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_Sanitize_TagGrammar(t *testing.T) {
+	type TestGrammar struct {
+		List  *string           `san:"def='a,b'"`
+		Trim  string            `san:"trim=\\,;,upper"`
+		Pair  *string           `san:"def=x=y,"`
+		Names map[string]string `san:"keys=trim='|'|upper"`
+	}
+	type TestMalformed struct {
+		Name string `san:"trim,def='x"`
+	}
+
+	s, _ := New()
+
+	v := &TestGrammar{
+		Trim:  ";,a,b;,",
+		Names: map[string]string{"|key|": "v"},
+	}
+	if err := s.Sanitize(v); err != nil {
+		t.Fatalf("Sanitize() - got unexpected error %v", err)
+	}
+	if *v.List != "a,b" || v.Trim != "A,B" || *v.Pair != "x=y" || v.Names["KEY"] != "v" {
+		t.Errorf("Sanitize() - got %q, %q, %q and %v", *v.List, v.Trim, *v.Pair, v.Names)
+	}
+
+	err := s.Sanitize(&TestMalformed{})
+	if err == nil || err.Error() != `field "Name" (def): unterminated quote in the value of tag component "def"` {
+		t.Errorf("Sanitize() - got error %v, wanted a parse error", err)
+	}
+
+	name := "a;b;"
+	if err := s.SanitizeValue(&name, `trim=';',def='\''`); err != nil || name != "a;b" {
+		t.Errorf("SanitizeValue() - got %q and error %v", name, err)
+	}
+	if err := s.SanitizeValue(&name, "trim='"); err == nil {
+		t.Error("SanitizeValue() - did not receive expected error")
+	}
+}
+
+func Test_Sanitize_OverrideTags(t *testing.T) {
+	type TestStruct struct {
+		Name   string   `san:"trim,lower,max=3"`
+		Names  []string `san:"lower,upper"`
+		Age    int      `san:"max=10"`
+		Ratio  *float64 `san:"max=1,def=5"`
+		Sizes  []int    `san:"maxsize=1"`
+		Hidden string   `san:"trim,maxsize"`
+	}
+
+	s, err := New(
+		OptionStringSanitizer{
+			Name: "lower",
+			Func: func(v, param string) (string, error) {
+				return strings.ToLower(strings.ReplaceAll(v, "I", "ı")), nil
+			},
+			Override: true,
+		},
+		OptionSanitizerFunc{Name: "maxsize", Sanitizer: doNothing, Override: true},
+		OptionFloatSanitizer{
+			Name: "max",
+			Func: func(v float64, param string) (float64, error) {
+				return v * 10, nil
+			},
+			Override: true,
+		},
+	)
+	if err != nil {
+		t.Fatalf("New() - got unexpected error %v", err)
+	}
+
+	v := &TestStruct{Name: " IRIS ", Names: []string{"Ii"}, Age: 42, Sizes: []int{1, 2}, Hidden: " h "}
+	if err := s.Sanitize(v); err != nil {
+		t.Fatalf("Sanitize() - got unexpected error %v", err)
+	}
+	if v.Name != "ırıs" || v.Names[0] != "II" || v.Age != 42 || *v.Ratio != 5 ||
+		len(v.Sizes) != 2 || v.Hidden != "h" {
+		t.Errorf("Sanitize() - got %+v", v)
+	}
+
+	ratio := 0.5
+	if err := s.SanitizeValue(&ratio, "max=1"); err != nil || ratio != 5 {
+		t.Errorf("SanitizeValue() - got %v and error %v", ratio, err)
+	}
+	if err := s.Validate(TestStruct{}); err == nil || !strings.Contains(err.Error(), `custom sanitizer "max" can not be used on type`) {
+		t.Errorf("Validate() - got error %v, wanted a type error", err)
+	}
+}
+
+func Test_Sanitize_BuiltinNameWithoutOverride(t *testing.T) {
+	type TestStruct struct {
+		Name string `san:"trim"`
+		Age  int    `san:"max=10"`
+	}
+
+	s, err := New(
+		OptionSanitizerFunc{Name: "trim", Sanitizer: appendSanitizer("!")},
+		OptionIntSanitizer{
+			Name: "max",
+			Func: func(v int64, param string) (int64, error) {
+				return v * 2, nil
+			},
+			After: true,
+		},
+	)
+	if err != nil {
+		t.Fatalf("New() - got unexpected error %v", err)
+	}
+
+	// The custom sanitizers run along with the built-in components
+	v := &TestStruct{Name: " a ", Age: 42}
+	if err := s.Sanitize(v); err != nil {
+		t.Fatalf("Sanitize() - got unexpected error %v", err)
+	}
+	if v.Name != "a !" || v.Age != 20 {
+		t.Errorf("Sanitize() - got %+v", v)
+	}
+	if err := s.Validate(TestStruct{}); err != nil {
+		t.Errorf("Validate() - got unexpected error %v", err)
+	}
+}
+
+func Test_Sanitize_DisableTags(t *testing.T) {
+	type TestAllowed struct {
+		Name string `san:"trim"`
+	}
+	type TestDisabled struct {
+		Name string `san:"trim,xss"`
+	}
+	type TestDisabledInSlice struct {
+		Subs []TestDisabled `san:"maxsize=2"`
+	}
+
+	s, _ := New(OptionDisableTags{Names: []string{"xss", "maxsize"}})
+
+	if err := s.Sanitize(&TestAllowed{}); err != nil {
+		t.Errorf("Sanitize() - got unexpected error %v", err)
+	}
+
+	for _, v := range []interface{}{&TestDisabled{}, &TestDisabledInSlice{}} {
+		err := s.Sanitize(v)
+		if err == nil || !strings.Contains(err.Error(), "is disabled") {
+			t.Errorf("Sanitize() - got error %v, wanted a disabled tag error", err)
+		}
+	}
+	err := s.Sanitize(&TestDisabled{})
+	if err == nil || err.Error() != `field "Name" (xss): tag component "xss" is disabled` {
+		t.Errorf("Sanitize() - got error %v", err)
+	}
+
+	name := " x "
+	if err := s.SanitizeValue(&name, "trim,xss"); err == nil {
+		t.Error("SanitizeValue() - did not receive expected error")
+	}
+}
@@ -0,0 +1,189 @@
+package sanitize
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// truncateCut is the kind of boundary a truncator cuts strings on.
+type truncateCut int
+
+const (
+	cutRune truncateCut = iota
+	cutGrapheme
+	cutWord
+)
+
+// truncator shortens strings to at most maxRunes runes and maxBytes bytes,
+// including the suffix that is added when a string is shortened. Strings are
+// never cut in the middle of a rune.
+type truncator struct {
+	maxRunes int
+	maxBytes int
+	cut      truncateCut
+	suffix   string
+}
+
+// compileTruncator parses the "max", "maxbytes", "cut" and "suffix" tag
+// components of a string field. It returns nil if there is no limit.
+func compileTruncator(tags map[string]string) (*truncator, error) {
+	t := &truncator{maxRunes: math.MaxInt, maxBytes: math.MaxInt}
+	found := false
+
+	limit := func(name string) (int, error) {
+		max, err := strconv.ParseInt(tags[name], 10, 32)
+		if err != nil {
+			return 0, tagError(name, err)
+		}
+		if max < 0 {
+			return 0, tagError(name, fmt.Errorf("%s on string field can not be below 0", name))
+		}
+		return int(max), nil
+	}
+	if _, ok := tags["max"]; ok {
+		max, err := limit("max")
+		if err != nil {
+			return nil, err
+		}
+		t.maxRunes = max
+		found = true
+	}
+	if _, ok := tags["maxbytes"]; ok {
+		max, err := limit("maxbytes")
+		if err != nil {
+			return nil, err
+		}
+		t.maxBytes = max
+		found = true
+	}
+
+	if v, ok := tags["cut"]; ok {
+		if !found {
+			return nil, tagError("cut", fmt.Errorf("cut needs a max or maxbytes tag component"))
+		}
+		switch v {
+		case "rune":
+			t.cut = cutRune
+		case "grapheme":
+			t.cut = cutGrapheme
+		case "word":
+			t.cut = cutWord
+		default:
+			return nil, tagError("cut", fmt.Errorf("unknown cut %q, expected rune, grapheme or word", v))
+		}
+	}
+	if v, ok := tags["suffix"]; ok {
+		if !found {
+			return nil, tagError("suffix", fmt.Errorf("suffix needs a max or maxbytes tag component"))
+		}
+		t.suffix = v
+	}
+
+	if !found {
+		return nil, nil
+	}
+	return t, nil
+}
+
+func (t *truncator) fits(s string) bool {
+	if len(s) <= t.maxRunes && len(s) <= t.maxBytes {
+		return true
+	}
+	return len(s) <= t.maxBytes && utf8.RuneCountInString(s) <= t.maxRunes
+}
+
+// truncate returns s if it fits, and otherwise its longest prefix that ends
+// on the right kind of boundary and fits along with the suffix. The suffix is
+// left out when it does not fit on its own.
+func (t *truncator) truncate(s string) string {
+	if t.fits(s) {
+		return s
+	}
+
+	maxRunes, maxBytes, suffix := t.maxRunes, t.maxBytes, t.suffix
+	if t.fits(suffix) {
+		maxRunes -= utf8.RuneCountInString(suffix)
+		maxBytes -= len(suffix)
+	} else {
+		suffix = ""
+	}
+
+	next := nextRune
+	if t.cut != cutRune {
+		next = nextGrapheme
+	}
+
+	end, runes := 0, 0
+	for end < len(s) {
+		i, n := next(s, end)
+		if runes+n > maxRunes || i > maxBytes {
+			break
+		}
+		end, runes = i, runes+n
+	}
+
+	if t.cut == cutWord {
+		// The cut splits a word unless it falls right before a space, in
+		// which case the whole word is dropped. Without any space, the cut is
+		// kept on a grapheme boundary.
+		if r, _ := utf8.DecodeRuneInString(s[end:]); !unicode.IsSpace(r) {
+			if i := strings.LastIndexFunc(s[:end], unicode.IsSpace); i >= 0 {
+				end = i
+			}
+		}
+		end = len(strings.TrimRightFunc(s[:end], unicode.IsSpace))
+	}
+
+	return s[:end] + suffix
+}
+
+// nextRune returns the index of the rune that follows the one starting at i,
+// and the number of runes in between, which is always 1.
+func nextRune(s string, i int) (int, int) {
+	_, size := utf8.DecodeRuneInString(s[i:])
+	return i + size, 1
+}
+
+// nextGrapheme returns the index of the grapheme cluster that follows the one
+// starting at i, and the number of runes in between. Clusters are
+// approximated: a rune is kept with the ones before it if it is a combining
+// mark, the second of a pair of regional indicators (a flag), an emoji
+// modifier or tag, or if it is joined to them with a zero width joiner. CR LF
+// is kept whole.
+func nextGrapheme(s string, i int) (int, int) {
+	prev, size := utf8.DecodeRuneInString(s[i:])
+	i += size
+	runes := 1
+	regional := 0
+	if isRegionalIndicator(prev) {
+		regional = 1
+	}
+
+	for i < len(s) {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case prev == '\r' && r == '\n',
+			prev == '\u200d',
+			r == '\u200d',
+			unicode.In(r, unicode.Mn, unicode.Me, unicode.Mc),
+			r >= 0x1f3fb && r <= 0x1f3ff,
+			r >= 0xe0020 && r <= 0xe007f:
+		case regional == 1 && isRegionalIndicator(r):
+			regional++
+		default:
+			return i, runes
+		}
+		prev = r
+		i += size
+		runes++
+	}
+	return i, runes
+}
+
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1f1e6 && r <= 0x1f1ff
+}
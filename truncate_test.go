@@ -0,0 +1,88 @@
+package sanitize
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_truncator_truncate(t *testing.T) {
+	tests := []struct {
+		name string
+		tags map[string]string
+		in   string
+		want string
+	}{
+		{name: "Keeps strings that fit.", tags: map[string]string{"max": "5"}, in: "héllo", want: "héllo"},
+		{name: "max counts runes, not bytes.", tags: map[string]string{"max": "4"}, in: "héllo wörld", want: "héll"},
+		{name: "max does not cut multi-byte runes.", tags: map[string]string{"max": "3"}, in: "日本語テキスト", want: "日本語"},
+		{name: "maxbytes stops before a rune that does not fit.", tags: map[string]string{"maxbytes": "5"}, in: "日本語", want: "日"},
+		{name: "The tighter of max and maxbytes wins.", tags: map[string]string{"max": "5", "maxbytes": "4"}, in: "abcdef", want: "abcd"},
+		{name: "The suffix counts towards max.", tags: map[string]string{"max": "5", "suffix": "…"}, in: "Hello world", want: "Hell…"},
+		{name: "The suffix counts towards maxbytes.", tags: map[string]string{"maxbytes": "5", "suffix": "…"}, in: "abcdef", want: "ab…"},
+		{name: "The suffix is only added to truncated strings.", tags: map[string]string{"max": "5", "suffix": "…"}, in: "Hello", want: "Hello"},
+		{name: "The suffix is left out when it does not fit.", tags: map[string]string{"max": "2", "suffix": "..."}, in: "Hello", want: "He"},
+		{name: "cut=rune splits combining marks.", tags: map[string]string{"max": "3", "cut": "rune"}, in: "e\u0301e\u0301e\u0301", want: "e\u0301e"},
+		{name: "cut=grapheme keeps combining marks.", tags: map[string]string{"max": "3", "cut": "grapheme"}, in: "e\u0301e\u0301e\u0301", want: "e\u0301"},
+		{name: "cut=grapheme keeps flags whole.", tags: map[string]string{"max": "3", "cut": "grapheme"}, in: "🇫🇷🇩🇪", want: "🇫🇷"},
+		{name: "cut=grapheme keeps emoji ZWJ sequences whole.", tags: map[string]string{"max": "6", "cut": "grapheme"}, in: "a\U0001F468\u200d\U0001F469\u200d\U0001F467b", want: "a\U0001F468\u200d\U0001F469\u200d\U0001F467"},
+		{name: "cut=grapheme keeps skin tones.", tags: map[string]string{"max": "2", "cut": "grapheme"}, in: "👍🏽👍🏽", want: "👍🏽"},
+		{name: "cut=grapheme keeps CR LF whole.", tags: map[string]string{"max": "2", "cut": "grapheme"}, in: "a\r\nb", want: "a"},
+		{name: "cut=word drops the word that does not fit.", tags: map[string]string{"max": "8", "cut": "word", "suffix": "…"}, in: "Hello brave world", want: "Hello…"},
+		{name: "cut=word keeps a word that ends at the cut.", tags: map[string]string{"max": "5", "cut": "word"}, in: "Hello world", want: "Hello"},
+		{name: "cut=word drops trailing spaces.", tags: map[string]string{"max": "7", "cut": "word"}, in: "Hello   world", want: "Hello"},
+		{name: "cut=word cuts long words on graphemes.", tags: map[string]string{"max": "5", "cut": "word"}, in: "Supercalifragilistic", want: "Super"},
+		{name: "max=0 empties the string.", tags: map[string]string{"max": "0", "suffix": "…"}, in: "Hello", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr, err := compileTruncator(tt.tags)
+			if err != nil || tr == nil {
+				t.Fatalf("compileTruncator() - got %v, %v", tr, err)
+			}
+			if got := tr.truncate(tt.in); got != tt.want {
+				t.Errorf("truncate(%q) - got %q but wanted %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_compileTruncator_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		tags    map[string]string
+		wantErr string
+	}{
+		{name: "Rejects a non-numeric maxbytes.", tags: map[string]string{"maxbytes": "no"}, wantErr: "(maxbytes)"},
+		{name: "Rejects a negative maxbytes.", tags: map[string]string{"maxbytes": "-1"}, wantErr: "maxbytes on string field can not be below 0"},
+		{name: "Rejects cut without a limit.", tags: map[string]string{"cut": "word"}, wantErr: "cut needs a max or maxbytes tag component"},
+		{name: "Rejects suffix without a limit.", tags: map[string]string{"suffix": "…"}, wantErr: "suffix needs a max or maxbytes tag component"},
+		{name: "Rejects unknown cuts.", tags: map[string]string{"max": "5", "cut": "sentence"}, wantErr: `unknown cut "sentence"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := compileTruncator(tt.tags)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("compileTruncator() - got error %v, wanted it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_Sanitize_Truncate(t *testing.T) {
+	type TestTruncate struct {
+		Title   string  `san:"trim,max=12,cut=word,suffix=…"`
+		Name    *string `san:"maxbytes=3"`
+		Summary string  `san:"max=3,upper"`
+	}
+
+	s, _ := New()
+
+	name := "Zoë Doe"
+	v := &TestTruncate{Title: "  Über die Brücke gehen  ", Name: &name, Summary: "ñandú"}
+	if err := s.Sanitize(v); err != nil {
+		t.Fatalf("Sanitize() - got unexpected error %v", err)
+	}
+	if v.Title != "Über die…" || *v.Name != "Zo" || v.Summary != "ÑAN" {
+		t.Errorf("Sanitize() - got %q, %q and %q", v.Title, *v.Name, v.Summary)
+	}
+}
@@ -0,0 +1,261 @@
+package sanitize
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// typedSanitizer is a custom sanitizer registered with one of the typed
+// options, such as OptionStringSanitizer. Unlike a SanitizerFunc, it works on
+// single values, so it is applied like the built-in components: to the values
+// behind pointers and in slices, arrays and maps, with defaults and paths
+// handled by the plan.
+type typedSanitizer struct {
+	// kind is the kind of the values it applies to, Int64 standing for every
+	// signed integer kind, Uint64 for every unsigned one and Float64 for both
+	// float kinds.
+	kind  reflect.Kind
+	after bool
+	// str is set for string sanitizers, so that they can take part in the
+	// string transforms, and apply for the others.
+	str   func(v, param string) (string, error)
+	apply func(v reflect.Value, param string) error
+}
+
+// typedKind returns the kind that typed sanitizers must have to apply to
+// values of kind k.
+func typedKind(k reflect.Kind) reflect.Kind {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return reflect.Int64
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return reflect.Uint64
+	case reflect.Float32, reflect.Float64:
+		return reflect.Float64
+	}
+	return k
+}
+
+func newStringSanitizer(o OptionStringSanitizer) *typedSanitizer {
+	return &typedSanitizer{
+		kind:  reflect.String,
+		after: o.After,
+		str:   o.Func,
+	}
+}
+
+func newIntSanitizer(o OptionIntSanitizer) *typedSanitizer {
+	return &typedSanitizer{
+		kind:  reflect.Int64,
+		after: o.After,
+		apply: func(v reflect.Value, param string) error {
+			n, err := o.Func(v.Int(), param)
+			if err != nil {
+				return err
+			}
+			if v.OverflowInt(n) {
+				return fmt.Errorf("%d overflows %s", n, v.Type())
+			}
+			v.SetInt(n)
+			return nil
+		},
+	}
+}
+
+func newUintSanitizer(o OptionUintSanitizer) *typedSanitizer {
+	return &typedSanitizer{
+		kind:  reflect.Uint64,
+		after: o.After,
+		apply: func(v reflect.Value, param string) error {
+			n, err := o.Func(v.Uint(), param)
+			if err != nil {
+				return err
+			}
+			if v.OverflowUint(n) {
+				return fmt.Errorf("%d overflows %s", n, v.Type())
+			}
+			v.SetUint(n)
+			return nil
+		},
+	}
+}
+
+func newFloatSanitizer(o OptionFloatSanitizer) *typedSanitizer {
+	return &typedSanitizer{
+		kind:  reflect.Float64,
+		after: o.After,
+		apply: func(v reflect.Value, param string) error {
+			n, err := o.Func(v.Float(), param)
+			if err != nil {
+				return err
+			}
+			if v.OverflowFloat(n) {
+				return fmt.Errorf("%v overflows %s", n, v.Type())
+			}
+			v.SetFloat(n)
+			return nil
+		},
+	}
+}
+
+func newBoolSanitizer(o OptionBoolSanitizer) *typedSanitizer {
+	return &typedSanitizer{
+		kind:  reflect.Bool,
+		after: o.After,
+		apply: func(v reflect.Value, param string) error {
+			b, err := o.Func(v.Bool(), param)
+			if err != nil {
+				return err
+			}
+			v.SetBool(b)
+			return nil
+		},
+	}
+}
+
+// registerSanitizer registers the typed sanitizer of the option o under its
+// name, which must not be used by any other custom sanitizer. Its Func must
+// be set.
+func (s *Sanitizer) registerSanitizer(o Option) error {
+	var name string
+	var override, hasFunc bool
+	var ts *typedSanitizer
+	switch v := o.value().(type) {
+	case OptionStringSanitizer:
+		name, override, hasFunc, ts = v.Name, v.Override, v.Func != nil, newStringSanitizer(v)
+	case OptionIntSanitizer:
+		name, override, hasFunc, ts = v.Name, v.Override, v.Func != nil, newIntSanitizer(v)
+	case OptionUintSanitizer:
+		name, override, hasFunc, ts = v.Name, v.Override, v.Func != nil, newUintSanitizer(v)
+	case OptionFloatSanitizer:
+		name, override, hasFunc, ts = v.Name, v.Override, v.Func != nil, newFloatSanitizer(v)
+	case OptionBoolSanitizer:
+		name, override, hasFunc, ts = v.Name, v.Override, v.Func != nil, newBoolSanitizer(v)
+	}
+
+	if name == "" {
+		return fmt.Errorf("sanitizer name can not be empty")
+	}
+	if !hasFunc {
+		return fmt.Errorf("sanitizer %q has no Func", name)
+	}
+	_, isFunc := s.sanitizersByName[name]
+	if _, ok := s.typedSanitizers[name]; ok || isFunc {
+		return fmt.Errorf("sanitizer already registered with name %q", name)
+	}
+	if err := s.registerOverride(name, override); err != nil {
+		return err
+	}
+	if s.typedSanitizers == nil {
+		s.typedSanitizers = make(map[string]*typedSanitizer)
+	}
+	s.typedSanitizers[name] = ts
+	return nil
+}
+
+// typedStep is a typed sanitizer along with the tag component that uses it,
+// and the phase of that component.
+type typedStep struct {
+	name  string
+	param string
+	ts    *typedSanitizer
+	phase int
+}
+
+// typedSteps returns the typed sanitizers in tags that apply to values of
+// type t, split into the ones that run before the built-in components and
+// the ones that run after them. Both are in the order of the tag.
+func (s *Sanitizer) typedSteps(t reflect.Type, tags map[string]string, order []string) (before, after []typedStep) {
+	var steps []typedStep
+	for name, param := range tags {
+		if ts, ok := s.typedSanitizers[name]; ok && ts.kind == typedKind(t.Kind()) {
+			phase := s.phaseAt(order, tagIndex(order, name))
+			steps = append(steps, typedStep{name: name, param: param, ts: ts, phase: phase})
+		}
+	}
+	sortTypedSteps(steps, order)
+
+	for _, step := range steps {
+		if step.ts.after {
+			after = append(after, step)
+		} else {
+			before = append(before, step)
+		}
+	}
+	return before, after
+}
+
+// sortTypedSteps sorts steps by their place in the tag, as written in order.
+// Steps are sorted by name first, so that they always run in the same order
+// when they are not in order.
+func sortTypedSteps(steps []typedStep, order []string) {
+	sort.Slice(steps, func(i, j int) bool {
+		return steps[i].name < steps[j].name
+	})
+	sort.SliceStable(steps, func(i, j int) bool {
+		return tagIndex(order, steps[i].name) < tagIndex(order, steps[j].name)
+	})
+}
+
+// strTransform returns the string sanitizer of step as a string transform.
+func (step typedStep) strTransform() strTransform {
+	return strTransform{
+		tag:   step.name,
+		phase: step.phase,
+		custom: func(v string) (string, error) {
+			return step.ts.str(v, step.param)
+		},
+	}
+}
+
+// withTypedSteps wraps the apply func of rule, so that the typed sanitizers
+// of tags that apply to values of type t run around it. With OptionTagOrder,
+// the built-in components run together at the place of the first one, and
+// the typed sanitizers run before or after them depending on where they are
+// written, whatever their After field says.
+func (s *Sanitizer) withTypedSteps(rule *scalarRule, t reflect.Type, tags map[string]string, order []string) {
+	before, after := s.typedSteps(t, tags, order)
+	if len(before) == 0 && len(after) == 0 {
+		return
+	}
+	if s.tagOrder {
+		steps := append(before, after...)
+		sortTypedSteps(steps, order)
+		first := s.firstIndex(order, tags)
+		before, after = nil, nil
+		for _, step := range steps {
+			if tagIndex(order, step.name) < first {
+				before = append(before, step)
+			} else {
+				after = append(after, step)
+			}
+		}
+	}
+
+	builtins := rule.apply
+	rule.apply = func(v reflect.Value, p int) error {
+		if err := applyTypedSteps(before, v, p); err != nil {
+			return err
+		}
+		if builtins != nil {
+			if err := builtins(v, p); err != nil {
+				return err
+			}
+		}
+		return applyTypedSteps(after, v, p)
+	}
+}
+
+// applyTypedSteps applies the steps of phase to v.
+func applyTypedSteps(steps []typedStep, v reflect.Value, phase int) error {
+	for _, step := range steps {
+		if !inPhase(phase, step.phase) {
+			continue
+		}
+		if err := step.ts.apply(v, step.param); err != nil {
+			return tagError(step.name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+package sanitize
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// typedOptions registers a typed sanitizer of every kind.
+var typedOptions = []Option{
+	OptionStringSanitizer{
+		Name: "wrap",
+		Func: func(v, param string) (string, error) {
+			if v == "fail" {
+				return "", errors.New("can not wrap fail")
+			}
+			return param + v + param, nil
+		},
+	},
+	OptionStringSanitizer{
+		Name: "dot",
+		Func: func(v, param string) (string, error) {
+			return v + ".", nil
+		},
+		After: true,
+	},
+	OptionIntSanitizer{
+		Name: "times",
+		Func: func(v int64, param string) (int64, error) {
+			n, err := parseInt64(param)
+			return v * n, err
+		},
+	},
+	OptionUintSanitizer{
+		Name: "plus",
+		Func: func(v uint64, param string) (uint64, error) {
+			n, err := parseUint64(param)
+			return v + n, err
+		},
+	},
+	OptionFloatSanitizer{
+		Name: "half",
+		Func: func(v float64, param string) (float64, error) {
+			return v / 2, nil
+		},
+		After: true,
+	},
+	OptionBoolSanitizer{
+		Name: "not",
+		Func: func(v bool, param string) (bool, error) {
+			return !v, nil
+		},
+	},
+}
+
+// keepString, keepInt, keepFloat and keepBool are typed sanitizers that
+// change nothing.
+func keepString(v, param string) (string, error) {
+	return v, nil
+}
+
+func keepInt(v int64, param string) (int64, error) {
+	return v, nil
+}
+
+func keepFloat(v float64, param string) (float64, error) {
+	return v, nil
+}
+
+func keepBool(v bool, param string) (bool, error) {
+	return v, nil
+}
+
+func Test_Sanitize_TypedSanitizers(t *testing.T) {
+	type TestSub struct {
+		Name string `san:"wrap=-"`
+	}
+	type TestStruct struct {
+		Name   string            `san:"wrap=*,trim=*,upper"`
+		Dotted string            `san:"dot,max=2"`
+		Def    *string           `san:"def=x,wrap=_"`
+		Names  []string          `san:"wrap=|"`
+		ByKey  map[string]string `san:"wrap=!"`
+		Int8   int8              `san:"times=2,max=50"`
+		Uint   *uint             `san:"plus=3,min=5"`
+		Float  float32           `san:"half,max=10"`
+		Bool   bool              `san:"not"`
+		Subs   []TestSub
+	}
+
+	s, err := New(typedOptions...)
+	if err != nil {
+		t.Fatalf("New() - got unexpected error %v", err)
+	}
+
+	uintVal := uint(1)
+	v := &TestStruct{
+		Name:   "ab",
+		Dotted: "abc",
+		Names:  []string{"a", "b"},
+		ByKey:  map[string]string{"k": "v"},
+		Int8:   40,
+		Uint:   &uintVal,
+		Float:  42,
+		Subs:   []TestSub{{Name: "s"}},
+	}
+	if err := s.Sanitize(v); err != nil {
+		t.Fatalf("Sanitize() - got unexpected error %v", err)
+	}
+
+	if v.Name != "AB" || v.Dotted != "ab." || *v.Def != "x" || strings.Join(v.Names, "") != "|a||b|" ||
+		v.ByKey["k"] != "!v!" || v.Int8 != 50 || *v.Uint != 5 || v.Float != 5 || !v.Bool ||
+		v.Subs[0].Name != "-s-" {
+		t.Errorf("Sanitize() - got %+v", v)
+	}
+}
+
+func Test_Sanitize_TypedSanitizerOrder(t *testing.T) {
+	type TestStruct struct {
+		Name  string  `san:"dot,max=3,wrap=-"`
+		Int8  int8    `san:"max=50,times=2,min=1"`
+		Float float32 `san:"half,max=10"`
+	}
+
+	s, _ := New(append(typedOptions, OptionTagOrder{Value: true})...)
+
+	// After is ignored, and numbers are clamped before times since max is
+	// written first
+	v := &TestStruct{Name: "abcd", Int8: 40, Float: 42}
+	if err := s.Sanitize(v); err != nil {
+		t.Fatalf("Sanitize() - got unexpected error %v", err)
+	}
+	if v.Name != "-abc-" || v.Int8 != 80 || v.Float != 10 {
+		t.Errorf("Sanitize() - got %+v with tag order", v)
+	}
+
+	name := "abcd"
+	if err := s.SanitizeValue(&name, "wrap=-,max=3,dot"); err != nil || name != "-ab." {
+		t.Errorf("SanitizeValue() - got %q and error %v with tag order", name, err)
+	}
+}
+
+func Test_Sanitize_TypedSanitizerErrors(t *testing.T) {
+	type TestSub struct {
+		Names []string `san:"wrap"`
+	}
+	type TestFail struct {
+		Sub TestSub
+	}
+	type TestOverflow struct {
+		Int8 int8 `san:"times=1000"`
+	}
+	type TestWrongKind struct {
+		Age int `san:"wrap"`
+	}
+
+	s, _ := New(typedOptions...)
+
+	err := s.Sanitize(&TestFail{Sub: TestSub{Names: []string{"ok", "fail"}}})
+	if err == nil || err.Error() != `field "Sub.Names[1]" (wrap): can not wrap fail` {
+		t.Errorf("Sanitize() - got error %v, wanted the error of the sanitizer", err)
+	}
+
+	err = s.Sanitize(&TestOverflow{Int8: 1})
+	if err == nil || err.Error() != `field "Int8" (times): 1000 overflows int8` {
+		t.Errorf("Sanitize() - got error %v, wanted an overflow error", err)
+	}
+
+	err = s.Validate(TestWrongKind{})
+	if err == nil || !strings.Contains(err.Error(), `custom sanitizer "wrap" can not be used on type int`) {
+		t.Errorf("Validate() - got error %v, wanted a type error", err)
+	}
+
+	for _, options := range [][]Option{
+		{OptionStringSanitizer{Name: "", Func: keepString}},
+		{OptionStringSanitizer{Name: "a", Func: keepString}, OptionBoolSanitizer{Name: "a", Func: keepBool}},
+		{OptionIntSanitizer{Name: "a", Func: keepInt}, OptionSanitizerFunc{Name: "a", Sanitizer: doNothing}},
+		{OptionSanitizerFunc{Name: "a", Sanitizer: doNothing}, OptionFloatSanitizer{Name: "a", Func: keepFloat}},
+		{OptionStringSanitizer{Name: "a"}},
+		{OptionIntSanitizer{Name: "a"}},
+		{OptionUintSanitizer{Name: "a"}},
+		{OptionFloatSanitizer{Name: "a"}},
+		{OptionBoolSanitizer{Name: "a"}},
+	} {
+		if _, err := New(options...); err == nil {
+			t.Errorf("New() - did not receive expected error for %+v", options)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package sanitize
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Validate checks every tag of a struct type, and of the struct types it
+// refers to, without sanitizing anything. v can be a reflect.Type, a struct,
+// or a pointer, slice, array or map of structs; only its type is used.
+// Unknown tag components, values that can not be parsed and components that
+// conflict with each other are reported, so Validate is meant to be called
+// from init() or from tests.
+func (s *Sanitizer) Validate(v interface{}) error {
+	_, err := s.validate(v)
+	return err
+}
+
+// Register validates the struct types of vs like Validate does, and caches
+// their plans so the first call to Sanitize does not have to compile them.
+func (s *Sanitizer) Register(vs ...interface{}) error {
+	for _, v := range vs {
+		compiled, err := s.validate(v)
+		if err != nil {
+			return err
+		}
+		if s.plans == nil {
+			continue
+		}
+		for t, p := range compiled {
+			s.plans.Store(t, p)
+		}
+	}
+	return nil
+}
+
+func (s *Sanitizer) validate(v interface{}) (map[reflect.Type]*structPlan, error) {
+	t, ok := v.(reflect.Type)
+	if !ok {
+		t = reflect.TypeOf(v)
+	}
+	if t == nil {
+		return nil, fmt.Errorf("can not validate a nil value")
+	}
+	for {
+		switch t.Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+			t = t.Elem()
+			continue
+		}
+		break
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("can not validate type %s, it is not a struct", t)
+	}
+
+	c := newPlanCompiler(s)
+	c.strict = true
+	if _, err := c.structPlan(t); err != nil {
+		return nil, err
+	}
+
+	return c.compiled, nil
+}
+
+// checkTags reports the components of tags that have no meaning for values of
+// type t, and the built-in components that conflict with each other.
+func (c *planCompiler) checkTags(t reflect.Type, tags map[string]string) error {
+	names := make([]string, 0, len(tags))
+	for name := range tags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if _, ok := c.s.sanitizersByName[name]; ok {
+			continue
+		}
+		if ts, ok := c.s.typedSanitizers[name]; ok {
+			// Without Override, the built-in component of the same name
+			// may still apply
+			builtin := !c.s.overrides[name] && tagKnownFor(t, name)
+			if ts.kind != typedKind(scalarKindOf(t)) && !builtin {
+				return tagError(name, fmt.Errorf("custom sanitizer %q can not be used on type %s", name, t))
+			}
+			continue
+		}
+		if !tagKnownFor(t, name) {
+			return tagError(name, fmt.Errorf("unknown tag component %q for type %s", name, t))
+		}
+	}
+
+	if scalarKindOf(t) == reflect.String {
+		if err := checkConflicts(c.s.builtinTags(tags), stringCaseTags); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkConflicts returns an error if tags holds more than one of the
+// components in group.
+func checkConflicts(tags map[string]string, group []string) error {
+	var found []string
+	for _, name := range group {
+		if _, ok := tags[name]; ok {
+			found = append(found, name)
+		}
+	}
+	if len(found) > 1 {
+		return tagError(found[1], fmt.Errorf(
+			"conflicting tag components %s, only one of them can be used",
+			strings.Join(found, " and "),
+		))
+	}
+	return nil
+}
+
+// tagKnownFor reports whether the built-in component name applies to values
+// of type t, or to any of the values it holds.
+func tagKnownFor(t reflect.Type, name string) bool {
+	for {
+		switch t.Kind() {
+		case reflect.Ptr:
+			t = t.Elem()
+			continue
+		case reflect.Slice:
+			if hasTag(sliceTags, name) {
+				return true
+			}
+			t = t.Elem()
+			continue
+		case reflect.Array:
+			t = t.Elem()
+			continue
+		case reflect.Map:
+			if hasTag(mapTags, name) {
+				return true
+			}
+			t = t.Elem()
+			continue
+		}
+		return hasTag(scalarTags[t.Kind()], name)
+	}
+}
+
+// scalarKindOf returns the kind of the values held by t once pointers,
+// slices, arrays and maps are peeled off.
+func scalarKindOf(t reflect.Type) reflect.Kind {
+	for {
+		switch t.Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+			t = t.Elem()
+			continue
+		}
+		return t.Kind()
+	}
+}
+
+func hasTag(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,217 @@
+package sanitize
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_Validate(t *testing.T) {
+	type TestGood struct {
+		Name    string    `san:"trim,lower,max=10"`
+		Def     *string   `san:"def=x"`
+		Age     *int      `san:"min=1,max=99,def=18"`
+		Ratio   float64   `san:"min=0.5"`
+		Active  *bool     `san:"def=true"`
+		Tags    []string  `san:"maxsize=3,trim"`
+		PtrTags *[]*int64 `san:"maxsize=3,min=2"`
+		Custom  string    `san:"capfirst"`
+	}
+	type TestGoodNested struct {
+		Sub    TestGood
+		SubPtr *TestGood
+		Subs   []TestGood `san:"maxsize=2"`
+	}
+	type TestUnknown struct {
+		Name string `san:"trim,shout"`
+	}
+	type TestUnknownForKind struct {
+		Age int `san:"trim"`
+	}
+	type TestMaxsizeNotSlice struct {
+		Name string `san:"maxsize=2"`
+	}
+	type TestBadMax struct {
+		Age int `san:"max=abc"`
+	}
+	type TestMaxBelowMin struct {
+		Age int `san:"min=5,max=2"`
+	}
+	type TestNegativeMin struct {
+		Age uint `san:"min=-1"`
+	}
+	type TestNegativeSigned struct {
+		Temp   int16    `san:"min=-40,max=85,def=-1"`
+		Offset *float64 `san:"min=-0.5,max=-0.1,def=-0.2"`
+	}
+	type TestBadStrMax struct {
+		Name *string `san:"max=abc"`
+	}
+	type TestBadBoolDef struct {
+		Active *bool `san:"def=maybe"`
+	}
+	type TestCaseConflict struct {
+		Name string `san:"lower,upper"`
+	}
+	type TestBadNested struct {
+		Sub *TestBadMax
+	}
+	type TestBadInSlice struct {
+		Subs []*TestUnknown
+	}
+
+	s, _ := New(OptionSanitizerFunc{Name: "capfirst", Sanitizer: capFirst})
+
+	tests := []struct {
+		name    string
+		v       interface{}
+		wantErr string
+	}{
+		{
+			name: "Accepts a struct with valid tags.",
+			v:    TestGood{},
+		},
+		{
+			name: "Accepts a pointer to a struct with valid tags.",
+			v:    &TestGood{},
+		},
+		{
+			name: "Accepts the reflect.Type of a struct with valid tags.",
+			v:    reflect.TypeOf(TestGoodNested{}),
+		},
+		{
+			name:    "Rejects unknown tag components.",
+			v:       TestUnknown{},
+			wantErr: `unknown tag component "shout"`,
+		},
+		{
+			name:    "Rejects tag components that do not apply to the field's type.",
+			v:       TestUnknownForKind{},
+			wantErr: `unknown tag component "trim"`,
+		},
+		{
+			name:    "Rejects slice tag components on a field that is not a slice.",
+			v:       TestMaxsizeNotSlice{},
+			wantErr: `unknown tag component "maxsize"`,
+		},
+		{
+			name:    "Rejects values that can not be parsed.",
+			v:       TestBadMax{},
+			wantErr: `field "Age" (max)`,
+		},
+		{
+			name:    "Rejects a max lower than the min.",
+			v:       TestMaxBelowMin{},
+			wantErr: "less than min",
+		},
+		{
+			name:    "Rejects a negative min on an unsigned field.",
+			v:       TestNegativeMin{},
+			wantErr: `field "Age" (min): min on uint field can not be below 0`,
+		},
+		{
+			name: "Accepts negative bounds on signed fields.",
+			v:    TestNegativeSigned{},
+		},
+		{
+			name:    "Rejects a bad string max on a pointer field.",
+			v:       TestBadStrMax{},
+			wantErr: `field "Name" (max)`,
+		},
+		{
+			name:    "Rejects a bad bool default, even though it is only used for nil pointers.",
+			v:       TestBadBoolDef{},
+			wantErr: "unable to parse default bool value",
+		},
+		{
+			name:    "Rejects conflicting string case components.",
+			v:       TestCaseConflict{},
+			wantErr: "conflicting tag components lower and upper",
+		},
+		{
+			name:    "Rejects bad tags in nested structs.",
+			v:       TestBadNested{},
+			wantErr: `field "Sub.Age" (max)`,
+		},
+		{
+			name:    "Rejects bad tags in slices of structs.",
+			v:       &TestBadInSlice{},
+			wantErr: `unknown tag component "shout"`,
+		},
+		{
+			name:    "Rejects values that are not structs.",
+			v:       "hello",
+			wantErr: "not a struct",
+		},
+		{
+			name:    "Rejects nil.",
+			v:       nil,
+			wantErr: "nil",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.Validate(tt.v)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() - got unexpected error %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("Validate() - did not receive expected error")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() - got error %q, wanted it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_Validate_IgnoresLenientPlans(t *testing.T) {
+	type TestStruct struct {
+		Name string `san:"trim,shout"`
+	}
+
+	s, _ := New()
+
+	if err := s.Sanitize(&TestStruct{}); err != nil {
+		t.Fatalf("Sanitize() - got unexpected error %v", err)
+	}
+	if err := s.Validate(TestStruct{}); err == nil {
+		t.Error("Validate() - did not receive expected error for a cached type")
+	}
+}
+
+func Test_Register(t *testing.T) {
+	type TestSub struct {
+		Name string `san:"trim"`
+	}
+	type TestStruct struct {
+		Sub []TestSub
+	}
+	type TestBadStruct struct {
+		Name string `san:"max=abc"`
+	}
+
+	s, _ := New()
+
+	if err := s.Register(TestStruct{}); err != nil {
+		t.Fatalf("Register() - got unexpected error %v", err)
+	}
+	for _, typ := range []reflect.Type{
+		reflect.TypeOf(TestStruct{}),
+		reflect.TypeOf(TestSub{}),
+	} {
+		if _, ok := s.plans.Load(typ); !ok {
+			t.Errorf("Register() - plan for %s was not cached", typ)
+		}
+	}
+
+	if err := s.Register(&TestBadStruct{}); err == nil {
+		t.Error("Register() - did not receive expected error")
+	}
+	if _, ok := s.plans.Load(reflect.TypeOf(TestBadStruct{})); ok {
+		t.Error("Register() - plan with bad tags was cached")
+	}
+}
@@ -0,0 +1,77 @@
+package sanitize
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// valuePlanKey identifies the plan of a value given to SanitizeValue.
+type valuePlanKey struct {
+	t   reflect.Type
+	tag string
+}
+
+// SanitizeValue applies the components of tag to the value v points to, the
+// same way they would be applied to a struct field with that tag. It is meant
+// for standalone values, such as query parameters or CLI arguments:
+//
+//	name := " Borky Borkins "
+//	err := s.SanitizeValue(&name, "trim,lower,max=5")
+//
+// v must be a pointer, or a slice or map whose elements can be changed in
+// place. Unlike struct tags, tag is always checked like Validate would, and
+// custom sanitizers can not be used since they work on struct fields.
+func (s *Sanitizer) SanitizeValue(v interface{}, tag string) error {
+	rv := reflect.ValueOf(v)
+	if err := checkMutable(rv); err != nil {
+		return err
+	}
+
+	plan, err := s.valuePlanFor(rv.Type(), tag)
+	if err != nil || plan == nil {
+		return err
+	}
+
+	w := newWalker(s)
+	if err := plan.apply(w, rv); err != nil {
+		return err
+	}
+	return w.err()
+}
+
+func (s *Sanitizer) valuePlanFor(t reflect.Type, tag string) (*valuePlan, error) {
+	key := valuePlanKey{t: t, tag: tag}
+	if s.roots != nil {
+		if p, ok := s.roots.Load(key); ok {
+			return p.(*valuePlan), nil
+		}
+	}
+
+	comps, err := parseTag(tag, ',')
+	if err != nil {
+		return nil, err
+	}
+	tags := tagComponents(comps)
+	order := tagNames(comps)
+	for name := range tags {
+		if _, ok := s.sanitizersByName[name]; ok {
+			return nil, tagError(name, fmt.Errorf("custom sanitizer %q can only be used on struct fields", name))
+		}
+	}
+
+	c := newPlanCompiler(s)
+	c.strict = true
+	if err := c.checkTags(t, tags); err != nil {
+		return nil, err
+	}
+	p, err := c.valuePlan(t, tags, order, nil)
+	if err != nil {
+		return nil, err
+	}
+	s.storePlans(c)
+	if s.roots != nil {
+		s.roots.LoadOrStore(key, p)
+	}
+
+	return p, nil
+}
@@ -0,0 +1,157 @@
+package sanitize
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_SanitizeValue(t *testing.T) {
+	type Email string
+
+	s, _ := New(OptionSanitizerFunc{Name: "capfirst", Sanitizer: capFirst})
+
+	str := func(v string) *string { return &v }
+	num := func(v int) *int { return &v }
+	var nilStr *string
+	email := Email(" Borky@Dogs.io ")
+
+	tests := []struct {
+		name    string
+		v       interface{}
+		tag     string
+		want    interface{}
+		wantErr string
+	}{
+		{
+			name: "Sanitizes a string.",
+			v:    str(" Borky Borkins "),
+			tag:  "trim,lower,max=5",
+			want: str("borky"),
+		},
+		{
+			name: "Sanitizes a defined string type.",
+			v:    &email,
+			tag:  "trim,lower",
+			want: func() *Email { v := Email("borky@dogs.io"); return &v }(),
+		},
+		{
+			name: "Sanitizes a number.",
+			v:    num(500),
+			tag:  "min=1,max=100",
+			want: num(100),
+		},
+		{
+			name: "Sets the default of a nil pointer.",
+			v:    &nilStr,
+			tag:  "def=none",
+			want: func() **string { v := str("none"); return &v }(),
+		},
+		{
+			name: "Sanitizes a pointer to a slice, including the slice itself.",
+			v:    &[]string{" a ", " b ", " c "},
+			tag:  "trim,maxsize=2",
+			want: &[]string{"a", "b"},
+		},
+		{
+			name: "Sanitizes the elements of a slice.",
+			v:    []*int{num(-5), num(5)},
+			tag:  "min=0",
+			want: []*int{num(0), num(5)},
+		},
+		{
+			name: "Sanitizes the values and keys of a map.",
+			v:    map[string]string{" A ": " B "},
+			tag:  "trim,keys=trim|lower",
+			want: map[string]string{"a": "B"},
+		},
+		{
+			name: "Does nothing with an empty tag.",
+			v:    str(" a "),
+			tag:  "",
+			want: str(" a "),
+		},
+		{
+			name:    "Returns an error when a slice passed by value must be resized.",
+			v:       []string{"a", "b"},
+			tag:     "maxsize=1",
+			want:    []string{"a", "b"},
+			wantErr: "passed by value",
+		},
+		{
+			name:    "Returns an error for unknown tag components.",
+			v:       str("a"),
+			tag:     "trim,shout",
+			want:    str("a"),
+			wantErr: `unknown tag component "shout"`,
+		},
+		{
+			name:    "Returns an error for components that do not apply to the type.",
+			v:       num(1),
+			tag:     "trim",
+			want:    num(1),
+			wantErr: `unknown tag component "trim"`,
+		},
+		{
+			name:    "Returns an error for bad component values.",
+			v:       num(1),
+			tag:     "max=abc",
+			want:    num(1),
+			wantErr: "(max)",
+		},
+		{
+			name:    "Returns an error for custom sanitizers.",
+			v:       str("a"),
+			tag:     "capfirst",
+			want:    str("a"),
+			wantErr: "can only be used on struct fields",
+		},
+		{
+			name:    "Returns an error for nil.",
+			v:       nil,
+			tag:     "trim",
+			wantErr: "can not sanitize nil",
+		},
+		{
+			name:    "Returns an error for a string passed by value.",
+			v:       "a",
+			tag:     "trim",
+			want:    "a",
+			wantErr: "passed by value",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.SanitizeValue(tt.v, tt.tag)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("SanitizeValue() - got error %v, wanted it to contain %q", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("SanitizeValue() - got unexpected error %v", err)
+			}
+			if !reflect.DeepEqual(tt.v, tt.want) {
+				t.Errorf("SanitizeValue() - got %+v but wanted %+v", tt.v, tt.want)
+			}
+		})
+	}
+}
+
+func Test_SanitizeValue_Cache(t *testing.T) {
+	s, _ := New()
+
+	v := " A "
+	if err := s.SanitizeValue(&v, "trim"); err != nil {
+		t.Fatalf("SanitizeValue() - got unexpected error %v", err)
+	}
+	if _, ok := s.roots.Load(valuePlanKey{t: reflect.TypeOf(&v), tag: "trim"}); !ok {
+		t.Error("SanitizeValue() - plan was not cached")
+	}
+
+	err := s.SanitizeValue(&v, "max=-1")
+	var fe *FieldError
+	if !errors.As(err, &fe) || fe.Tag != "max" {
+		t.Errorf("SanitizeValue() - got error %v, wanted a *FieldError for max", err)
+	}
+}
@@ -0,0 +1,76 @@
+package sanitize
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// collapseSpace replaces every run of Unicode whitespace in s with a single
+// space. Unlike strings.Fields, it keeps a leading or trailing space.
+func collapseSpace(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	inSpace := false
+	for _, r := range s {
+		if unicode.IsSpace(r) {
+			if !inSpace {
+				b.WriteByte(' ')
+			}
+			inSpace = true
+			continue
+		}
+		inSpace = false
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// compileNoControl parses the "nocontrol" tag component, whose value lists
+// the characters to keep: "tab", "newline" (CR, LF and NEL), or both, as in
+// nocontrol=tab|newline.
+func compileNoControl(v string) (func(string) string, error) {
+	keepTab, keepNewline := false, false
+	if v != "" {
+		for _, keep := range strings.Split(v, "|") {
+			switch keep {
+			case "tab":
+				keepTab = true
+			case "newline":
+				keepNewline = true
+			default:
+				return nil, tagError("nocontrol", fmt.Errorf("unknown nocontrol exception %q, expected tab or newline", keep))
+			}
+		}
+	}
+
+	return func(s string) string {
+		return strings.Map(func(r rune) rune {
+			switch {
+			case r == '\t' && keepTab,
+				(r == '\n' || r == '\r' || r == '\u0085') && keepNewline:
+				return r
+			case unicode.IsControl(r),
+				isZeroWidthSpace(r):
+				return -1
+			}
+			return r
+		}, s)
+	}, nil
+}
+
+// isZeroWidthSpace reports whether r is an invisible character that only
+// splits or joins words: zero width space, word joiner and byte order mark.
+// Zero width joiners and non-joiners are left alone, since emoji sequences
+// and some scripts need them.
+func isZeroWidthSpace(r rune) bool {
+	return r == '\u200b' || r == '\u2060' || r == '\ufeff'
+}
+
+// Newline replacers, by value of the "newlines" tag component. Besides CR LF,
+// CR and LF, the Unicode next line, line separator and paragraph separator
+// characters are replaced.
+var newlineReplacers = map[string]*strings.Replacer{
+	"lf":   strings.NewReplacer("\r\n", "\n", "\r", "\n", "\u0085", "\n", "\u2028", "\n", "\u2029", "\n"),
+	"crlf": strings.NewReplacer("\r\n", "\r\n", "\r", "\r\n", "\n", "\r\n", "\u0085", "\r\n", "\u2028", "\r\n", "\u2029", "\r\n"),
+}
@@ -0,0 +1,100 @@
+package sanitize
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_collapseSpace(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "no runs", want: "no runs"},
+		{in: "a  \t b", want: "a b"},
+		{in: "\n\na  b  ", want: " a b "},
+		{in: "", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			if got := collapseSpace(tt.in); got != tt.want {
+				t.Errorf("collapseSpace(%q) - got %q but wanted %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_Sanitize_Whitespace(t *testing.T) {
+	type TestWhitespace struct {
+		Search   string   `san:"nocontrol,collapse,trim,lower"`
+		Comment  *string  `san:"nocontrol=tab|newline,newlines=lf"`
+		Tabbed   string   `san:"nocontrol=tab"`
+		Windows  string   `san:"newlines=crlf"`
+		Left     string   `san:"trimleft"`
+		Right    string   `san:"trimright=.!"`
+		Prefixed []string `san:"trimleft=0"`
+	}
+
+	s, _ := New()
+
+	comment := "line one\r\nline\u200btwo\rline\tthree\x00\u0085end"
+	v := &TestWhitespace{
+		Search:   " \t Hello  WORLD\x07\u200b \r\n",
+		Comment:  &comment,
+		Tabbed:   "a\tb\nc\x1b\u009b",
+		Windows:  "a\nb\r\nc\rd",
+		Left:     "  left  ",
+		Right:    "..right!..",
+		Prefixed: []string{"007", "100"},
+	}
+	if err := s.Sanitize(v); err != nil {
+		t.Fatalf("Sanitize() - got unexpected error %v", err)
+	}
+
+	want := TestWhitespace{
+		Search:   "hello world",
+		Tabbed:   "a\tbc",
+		Windows:  "a\r\nb\r\nc\r\nd",
+		Left:     "left  ",
+		Right:    "..right",
+		Prefixed: []string{"7", "100"},
+	}
+	if v.Search != want.Search || v.Tabbed != want.Tabbed || v.Windows != want.Windows ||
+		v.Left != want.Left || v.Right != want.Right || strings.Join(v.Prefixed, ",") != "7,100" {
+		t.Errorf("Sanitize() - got %+q", []string{v.Search, v.Tabbed, v.Windows, v.Left, v.Right})
+	}
+	if *v.Comment != "line one\nlinetwo\nline\tthree\nend" {
+		t.Errorf("Sanitize() - got comment %q", *v.Comment)
+	}
+}
+
+func Test_Sanitize_WhitespaceErrors(t *testing.T) {
+	type TestBadNoControl struct {
+		Name string `san:"nocontrol=space"`
+	}
+	type TestBadNewlines struct {
+		Name string `san:"newlines=cr"`
+	}
+	type TestCollapseNewlines struct {
+		Name string `san:"collapse,newlines=lf"`
+	}
+
+	tests := []struct {
+		name    string
+		v       interface{}
+		wantErr string
+	}{
+		{name: "Rejects unknown nocontrol exceptions.", v: &TestBadNoControl{}, wantErr: `unknown nocontrol exception "space"`},
+		{name: "Rejects unknown newlines.", v: &TestBadNewlines{}, wantErr: `unknown newlines "cr"`},
+		{name: "Rejects collapse with newlines.", v: &TestCollapseNewlines{}, wantErr: "conflicting tag components newlines and collapse"},
+	}
+	s, _ := New()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.Sanitize(tt.v)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Sanitize() - got error %v, wanted it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}